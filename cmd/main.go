@@ -1,10 +1,12 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
 
 	"github.com/FiveEightyEight/mwfapi/db"
+	"github.com/FiveEightyEight/mwfapi/game"
 	"github.com/FiveEightyEight/mwfapi/handlers"
 	"github.com/gorilla/websocket"
 	"github.com/labstack/echo/v4"
@@ -28,6 +30,15 @@ func main() {
 
 	rdb := db.NewRedisClient(opts)
 
+	// Rate problems by how players have actually done on them
+	samples, err := rdb.GetDifficultySamples(context.Background())
+	if err != nil {
+		log.Printf("Failed to load difficulty samples: %v", err)
+	} else if len(samples) > 0 {
+		game.DefaultDifficultyModel = game.DefaultDifficultyModel.Calibrate(samples)
+		log.Printf("Calibrated problem difficulty on %d samples", len(samples))
+	}
+
 	e := echo.New()
 	e.HideBanner = true
 	e.Use(middleware.Logger())
@@ -61,6 +72,17 @@ func main() {
 	gameGroup.Use(handlers.AuthMiddleware)
 	gameGroup.POST("/game/create", handlers.CreateGame(rdb))
 	gameGroup.GET("/game/:game_session_id", handlers.ConnectToGameSession(rdb, upgrader))
+	gameGroup.GET("/game/:game_session_id/history", handlers.GetGameSessionHistory(rdb))
+	gameGroup.GET("/daily", handlers.GetDailyChallenge(rdb))
+	gameGroup.POST("/daily/start", handlers.StartDailyChallenge(rdb))
+	gameGroup.POST("/daily/submit", handlers.SubmitDailyChallenge(rdb))
+	gameGroup.GET("/daily/leaderboard", handlers.GetDailyLeaderboard(rdb))
+	gameGroup.POST("/challenge", handlers.CreateChallenge(rdb))
+	gameGroup.GET("/challenge/:challenge_id", handlers.GetChallenge(rdb))
+	gameGroup.POST("/challenge/:challenge_id/accept", handlers.AcceptChallenge(rdb))
+	gameGroup.POST("/challenge/:challenge_id/submit", handlers.SubmitChallenge(rdb))
+	gameGroup.GET("/review", handlers.GetReviewQueue(rdb))
+	gameGroup.DELETE("/review", handlers.ResetReviewQueue(rdb))
 
 	port := ":8088"
 	e.Logger.Fatal(e.Start("0.0.0.0" + port))
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/FiveEightyEight/mwfapi/models"
 	"github.com/google/uuid"
@@ -137,16 +138,73 @@ func (rc *RedisClient) UpdateGameSession(ctx context.Context, gameSession *model
 	return rc.PublishGameSessionUpdate(ctx, gameSession)
 }
 
+// sessionUpdateAttempts is how many times ModifyGameSession tries again when
+// another update saves the session first.
+const sessionUpdateAttempts = 10
+
+// ModifyGameSession applies modify to the stored game session, saves it and
+// publishes the result. If another update saves the session in between, the
+// change is applied again to the newer session, so concurrent updates can't
+// undo each other. An error from modify leaves the session as it was.
+func (rc *RedisClient) ModifyGameSession(ctx context.Context, id uuid.UUID, modify func(*models.GameSession) error) (*models.GameSession, error) {
+	key := fmt.Sprintf("game_session:%s", id)
+	for attempt := 0; attempt < sessionUpdateAttempts; attempt++ {
+		var gameSession models.GameSession
+		err := rc.client.Watch(ctx, func(tx *redis.Tx) error {
+			gameSessionJSON, err := tx.Get(ctx, key).Bytes()
+			if err != nil {
+				return err
+			}
+			gameSession = models.GameSession{}
+			if err := json.Unmarshal(gameSessionJSON, &gameSession); err != nil {
+				return err
+			}
+			if err := modify(&gameSession); err != nil {
+				return err
+			}
+			gameSessionJSON, err = json.Marshal(&gameSession)
+			if err != nil {
+				return err
+			}
+
+			_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+				pipe.Set(ctx, key, gameSessionJSON, 0)
+				return nil
+			})
+			return err
+		}, key)
+		// Another update saved the session first, apply the change to it
+		if err == redis.TxFailedErr {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &gameSession, rc.PublishGameSessionUpdate(ctx, &gameSession)
+	}
+	return nil, redis.TxFailedErr
+}
+
+// sessionDeleted is published on a game session's channel when the session
+// is deleted, ending every subscription to it.
+const sessionDeleted = "deleted"
+
 func (rc *RedisClient) DeleteGameSession(ctx context.Context, id uuid.UUID) error {
 	err := rc.client.Del(ctx, fmt.Sprintf("game_session:%s", id)).Err()
 	if err != nil {
 		return err
 	}
+	err = rc.client.Publish(ctx, fmt.Sprintf("game_session:%s", id), sessionDeleted).Err()
+	if err != nil {
+		return err
+	}
 	return rc.UpdateActiveGameSessions(ctx, id, false)
 }
 
 // Subscribe to GameSession
 
+// SubscribeToGameSession streams updates to the game session. The channel is
+// closed when ctx is done or the session is deleted.
 func (rc *RedisClient) SubscribeToGameSession(ctx context.Context, gameSessionID uuid.UUID) (<-chan *models.GameSession, error) {
 	pubsub := rc.client.Subscribe(ctx, fmt.Sprintf("game_session:%s", gameSessionID))
 
@@ -158,7 +216,7 @@ func (rc *RedisClient) SubscribeToGameSession(ctx context.Context, gameSessionID
 
 		for {
 			msg, err := pubsub.ReceiveMessage(ctx)
-			if err != nil {
+			if err != nil || msg.Payload == sessionDeleted {
 				return
 			}
 
@@ -224,3 +282,259 @@ func (rc *RedisClient) UpdateActiveGameSessions(ctx context.Context, gameSession
 		return rc.client.SRem(ctx, key, gameSessionID.String()).Err()
 	}
 }
+
+// DailyChallenge operations
+
+const dailyChallengeTTL = 30 * 24 * time.Hour
+
+// StartDailyAttempt records the start of a user's daily challenge attempt.
+// It returns false if the user already has an attempt for that date.
+func (rc *RedisClient) StartDailyAttempt(ctx context.Context, date string, attempt *models.AttemptResult) (bool, error) {
+	attemptJSON, err := json.Marshal(attempt)
+	if err != nil {
+		return false, err
+	}
+	return rc.client.SetNX(ctx, fmt.Sprintf("daily_attempt:%s:%s", date, attempt.UserID), attemptJSON, dailyChallengeTTL).Result()
+}
+
+// GetDailyAttempt returns the user's attempt for date, or nil if they have not started one.
+func (rc *RedisClient) GetDailyAttempt(ctx context.Context, date string, userID uuid.UUID) (*models.AttemptResult, error) {
+	attemptJSON, err := rc.client.Get(ctx, fmt.Sprintf("daily_attempt:%s:%s", date, userID)).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var attempt models.AttemptResult
+	err = json.Unmarshal(attemptJSON, &attempt)
+	return &attempt, err
+}
+
+// CompleteDailyAttempt saves a finished attempt and ranks it on the date's
+// leaderboard. It returns false, saving nothing, if the attempt was not
+// started or has already been completed, so only one submission counts.
+func (rc *RedisClient) CompleteDailyAttempt(ctx context.Context, date string, attempt *models.AttemptResult) (bool, error) {
+	attemptJSON, err := json.Marshal(attempt)
+	if err != nil {
+		return false, err
+	}
+
+	key := fmt.Sprintf("daily_attempt:%s:%s", date, attempt.UserID)
+	completed := false
+	err = rc.client.Watch(ctx, func(tx *redis.Tx) error {
+		storedJSON, err := tx.Get(ctx, key).Bytes()
+		if err == redis.Nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var stored models.AttemptResult
+		if err := json.Unmarshal(storedJSON, &stored); err != nil {
+			return err
+		}
+		if stored.CompletedAt != nil {
+			return nil
+		}
+
+		// Rank by points, then by the fastest time
+		leaderboardKey := fmt.Sprintf("daily_leaderboard:%s", date)
+		score := float64(attempt.Points)*1e9 - float64(attempt.TotalTimeMs)
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, key, attemptJSON, dailyChallengeTTL)
+			pipe.ZAdd(ctx, leaderboardKey, redis.Z{Score: score, Member: attempt.UserID.String()})
+			pipe.Expire(ctx, leaderboardKey, dailyChallengeTTL)
+			return nil
+		})
+		completed = err == nil
+		return err
+	}, key)
+	// Another submission changed the attempt first
+	if err == redis.TxFailedErr {
+		return false, nil
+	}
+	return completed, err
+}
+
+func (rc *RedisClient) GetDailyLeaderboard(ctx context.Context, date string, limit int64) ([]*models.AttemptResult, error) {
+	userIDs, err := rc.client.ZRevRange(ctx, fmt.Sprintf("daily_leaderboard:%s", date), 0, limit-1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	leaderboard := []*models.AttemptResult{}
+	for _, idStr := range userIDs {
+		id, err := uuid.Parse(idStr)
+		if err != nil {
+			continue
+		}
+		attempt, err := rc.GetDailyAttempt(ctx, date, id)
+		if err != nil || attempt == nil {
+			continue
+		}
+		leaderboard = append(leaderboard, attempt)
+	}
+
+	return leaderboard, nil
+}
+
+// Challenge operations
+
+func (rc *RedisClient) CreateChallenge(ctx context.Context, challenge *models.Challenge) error {
+	challengeJSON, err := json.Marshal(challenge)
+	if err != nil {
+		return err
+	}
+	return rc.client.Set(ctx, fmt.Sprintf("challenge:%s", challenge.ID), challengeJSON, time.Until(challenge.ExpiresAt)).Err()
+}
+
+// GetChallenge returns the challenge, or nil if it does not exist or has expired.
+func (rc *RedisClient) GetChallenge(ctx context.Context, id uuid.UUID) (*models.Challenge, error) {
+	challengeJSON, err := rc.client.Get(ctx, fmt.Sprintf("challenge:%s", id)).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var challenge models.Challenge
+	err = json.Unmarshal(challengeJSON, &challenge)
+	return &challenge, err
+}
+
+// TransitionChallenge saves challenge if it is still in status from,
+// without extending its expiry. It returns false, saving nothing, if the
+// challenge has expired or another request moved it on first.
+func (rc *RedisClient) TransitionChallenge(ctx context.Context, challenge *models.Challenge, from models.ChallengeStatus) (bool, error) {
+	challengeJSON, err := json.Marshal(challenge)
+	if err != nil {
+		return false, err
+	}
+
+	key := fmt.Sprintf("challenge:%s", challenge.ID)
+	transitioned := false
+	err = rc.client.Watch(ctx, func(tx *redis.Tx) error {
+		storedJSON, err := tx.Get(ctx, key).Bytes()
+		if err == redis.Nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var stored models.Challenge
+		if err := json.Unmarshal(storedJSON, &stored); err != nil {
+			return err
+		}
+		if stored.Status != from {
+			return nil
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.SetArgs(ctx, key, challengeJSON, redis.SetArgs{KeepTTL: true, Mode: "XX"})
+			return nil
+		})
+		transitioned = err == nil
+		return err
+	}, key)
+	if err == redis.TxFailedErr {
+		return false, nil
+	}
+	return transitioned, err
+}
+
+// Review queue operations
+
+// GetReviewQueue returns every fact in the user's review queue.
+func (rc *RedisClient) GetReviewQueue(ctx context.Context, userID uuid.UUID) ([]models.ReviewItem, error) {
+	itemsJSON, err := rc.client.HGetAll(ctx, fmt.Sprintf("review_queue:%s", userID)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	items := []models.ReviewItem{}
+	for _, itemJSON := range itemsJSON {
+		var item models.ReviewItem
+		if err := json.Unmarshal([]byte(itemJSON), &item); err != nil {
+			continue
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// SaveReviewItems adds items to the user's review queue, replacing any
+// already queued under the same key.
+func (rc *RedisClient) SaveReviewItems(ctx context.Context, userID uuid.UUID, items ...models.ReviewItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+	values := make([]interface{}, 0, len(items)*2)
+	for _, item := range items {
+		itemJSON, err := json.Marshal(item)
+		if err != nil {
+			return err
+		}
+		values = append(values, item.Key, itemJSON)
+	}
+	return rc.client.HSet(ctx, fmt.Sprintf("review_queue:%s", userID), values...).Err()
+}
+
+// RemoveReviewItems takes the facts with the given keys out of the user's review queue.
+func (rc *RedisClient) RemoveReviewItems(ctx context.Context, userID uuid.UUID, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return rc.client.HDel(ctx, fmt.Sprintf("review_queue:%s", userID), keys...).Err()
+}
+
+// ResetReviewQueue empties the user's review queue.
+func (rc *RedisClient) ResetReviewQueue(ctx context.Context, userID uuid.UUID) error {
+	return rc.client.Del(ctx, fmt.Sprintf("review_queue:%s", userID)).Err()
+}
+
+// Difficulty sample operations
+
+// maxDifficultySamples caps how many difficulty samples are kept, the oldest
+// being dropped first.
+const maxDifficultySamples = 10000
+
+// AddDifficultySamples records how players did on problems, for calibrating
+// problem difficulty.
+func (rc *RedisClient) AddDifficultySamples(ctx context.Context, samples ...models.DifficultySample) error {
+	if len(samples) == 0 {
+		return nil
+	}
+	values := make([]interface{}, len(samples))
+	for i, sample := range samples {
+		sampleJSON, err := json.Marshal(sample)
+		if err != nil {
+			return err
+		}
+		values[i] = sampleJSON
+	}
+	_, err := rc.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.RPush(ctx, "difficulty_samples", values...)
+		pipe.LTrim(ctx, "difficulty_samples", -maxDifficultySamples, -1)
+		return nil
+	})
+	return err
+}
+
+// GetDifficultySamples returns the recorded difficulty samples, oldest first.
+func (rc *RedisClient) GetDifficultySamples(ctx context.Context) ([]models.DifficultySample, error) {
+	samplesJSON, err := rc.client.LRange(ctx, "difficulty_samples", 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	samples := make([]models.DifficultySample, 0, len(samplesJSON))
+	for _, sampleJSON := range samplesJSON {
+		var sample models.DifficultySample
+		if err := json.Unmarshal([]byte(sampleJSON), &sample); err != nil {
+			continue
+		}
+		samples = append(samples, sample)
+	}
+	return samples, nil
+}
@@ -0,0 +1,119 @@
+package game
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"math/rand"
+	"slices"
+
+	"github.com/FiveEightyEight/mwfapi/models"
+	"github.com/google/uuid"
+)
+
+const (
+	MinLevel              = 1
+	MaxLevel              = 10
+	DefaultAdaptiveWindow = 5
+	MaxAdaptiveWindow     = 20
+	DefaultTargetMs       = 8000
+	MaxTargetMs           = 60000
+
+	// A window answered at least this accurately within the target time
+	// moves a player up a level, and one below demoteAccuracy down a level.
+	promoteAccuracy = 0.8
+	demoteAccuracy  = 0.5
+
+	minLevelSpan = 10
+)
+
+func adaptiveSettings(config models.GameConfig) models.GameConfigAdaptive {
+	var settings models.GameConfigAdaptive
+	if config.Adaptive != nil {
+		settings = *config.Adaptive
+	}
+	if settings.MinLevel == 0 {
+		settings.MinLevel = MinLevel
+	}
+	if settings.MaxLevel == 0 {
+		settings.MaxLevel = MaxLevel
+	}
+	if settings.StartLevel == 0 {
+		settings.StartLevel = settings.MinLevel
+	}
+	if settings.Window == 0 {
+		settings.Window = DefaultAdaptiveWindow
+	}
+	if settings.TargetMs == 0 {
+		settings.TargetMs = DefaultTargetMs
+	}
+	return settings
+}
+
+// levelConfig is the config for problems of method at level. Difficulty
+// grows with the size of the operands: level MaxLevel draws from the whole
+// range and lower levels from a proportionally narrower part of it around
+// the value nearest zero, never narrower than minLevelSpan either side.
+func levelConfig(config models.GameConfig, method models.GameConfigMethod, level int) models.GameConfig {
+	config = methodConfig(config, method)
+	r := config.Range
+	limit := max(minLevelSpan, max(abs(r.Min), abs(r.Max))*level/MaxLevel)
+	anchor := max(r.Min, min(0, r.Max))
+	config.Range = models.GameConfigRange{Min: max(r.Min, anchor-limit), Max: min(r.Max, anchor+limit)}
+	return config
+}
+
+// GenerateAdaptiveProblem builds the problem at index in the run of the player
+// userID at the given level, drawing again when it is one of asked, the
+// ReviewKey of each problem the player has already answered. Like
+// GenerateGameProblems, the same seed, player, index, level and asked always
+// give the same problem, while players at the same point of a run get
+// problems of their own.
+func GenerateAdaptiveProblem(config models.GameConfig, seed int64, userID uuid.UUID, index, level int, asked []string) models.GameProblem {
+	random := rand.New(rand.NewSource(playerSeed(seed, userID) + int64(index)*(MaxLevel+1) + int64(level)))
+	var problem models.GameProblem
+	for attempt := 0; attempt < duplicateAttempts; attempt++ {
+		method := config.Methods[random.Intn(len(config.Methods))]
+		problem = generateProblem(levelConfig(config, method, level), method, random)
+		if !slices.Contains(asked, ReviewKey(problem)) {
+			break
+		}
+	}
+	if config.MultipleChoice {
+		addChoices(config, &problem, random)
+	}
+	return problem
+}
+
+// playerSeed mixes userID into a session's seed, giving each player a seed of
+// their own.
+func playerSeed(seed int64, userID uuid.UUID) int64 {
+	var data [8]byte
+	binary.BigEndian.PutUint64(data[:], uint64(seed))
+	sum := sha256.Sum256(append(data[:], userID[:]...))
+	return int64(binary.BigEndian.Uint64(sum[:8]) & MaxSeed)
+}
+
+// NextLevel is the level a player moves to given their recent results.
+// Nothing changes until a full window of results has been collected.
+func NextLevel(config models.GameConfig, level int, recent []models.ProblemResult) int {
+	settings := adaptiveSettings(config)
+	if len(recent) < settings.Window {
+		return level
+	}
+
+	correct, totalMs := 0, 0
+	for _, result := range recent {
+		if result.Correct {
+			correct++
+		}
+		totalMs += result.TimeMs
+	}
+	accuracy := float64(correct) / float64(len(recent))
+	switch {
+	case accuracy >= promoteAccuracy && totalMs/len(recent) <= settings.TargetMs:
+		level++
+	case accuracy < demoteAccuracy:
+		level--
+	}
+	return max(settings.MinLevel, min(level, settings.MaxLevel))
+}
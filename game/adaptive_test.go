@@ -0,0 +1,62 @@
+package game
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/FiveEightyEight/mwfapi/models"
+	"github.com/google/uuid"
+)
+
+func TestGenerateAdaptiveProblem(t *testing.T) {
+	config := models.GameConfig{
+		Methods: []models.GameConfigMethod{models.GameConfigMethodAdd, models.GameConfigMethodMultiply},
+		Range:   models.GameConfigRange{Min: 1, Max: 100},
+		Mode:    models.GameModeAdaptive,
+	}
+	alice, bob := uuid.New(), uuid.New()
+
+	same := 0
+	for index := 0; index < DefaultProblemCount; index++ {
+		for level := MinLevel; level <= MaxLevel; level++ {
+			problem := GenerateAdaptiveProblem(config, 42, alice, index, level, nil)
+			if !reflect.DeepEqual(problem, GenerateAdaptiveProblem(config, 42, alice, index, level, nil)) {
+				t.Fatalf("index %d level %d: problem differs between runs", index, level)
+			}
+			if reflect.DeepEqual(problem, GenerateAdaptiveProblem(config, 42, bob, index, level, nil)) {
+				same++
+			}
+		}
+	}
+	if total := DefaultProblemCount * (MaxLevel - MinLevel + 1); same > total/4 {
+		t.Errorf("players shared %d of %d problems", same, total)
+	}
+}
+
+func TestAdaptiveProgressNoRepeats(t *testing.T) {
+	// Level 1 of sums from 1 to 10 has only 55 problems to give
+	config := models.GameConfig{
+		Methods:      []models.GameConfigMethod{models.GameConfigMethodAdd},
+		Range:        models.GameConfigRange{Min: 1, Max: 10},
+		Mode:         models.GameModeAdaptive,
+		ProblemCount: 30,
+		Adaptive:     &models.GameConfigAdaptive{MaxLevel: 1},
+	}
+	now := time.Now()
+	for seed := int64(0); seed < 20; seed++ {
+		progress := StartProgress(config, seed, uuid.New(), nil, now)
+		seen := map[string]bool{}
+		for progress.Problem != nil {
+			key := problemKey(*progress.Problem)
+			if seen[key] {
+				t.Fatalf("seed %d: problem %d repeats %s", seed, progress.Answered, key)
+			}
+			seen[key] = true
+			AdvanceProgress(config, seed, &progress, true, now)
+		}
+		if len(seen) != 30 {
+			t.Fatalf("seed %d: expected 30 problems, got %d", seed, len(seen))
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package game
+
+import (
+	"math/big"
+
+	"github.com/FiveEightyEight/mwfapi/models"
+)
+
+// CheckAnswer reports whether submitted is a correct answer to problem.
+// Numbers are compared exactly, so 2.9 is never taken for 2, and decimal
+// problems accept anything within the config's tolerance. Equivalent forms
+// such as 2/4 and 1/2 are accepted unless the config's fraction settings
+// require simplest form. Lists such as a prime factorization may be given
+// in any order.
+func CheckAnswer(config models.GameConfig, problem models.GameProblem, submitted models.Value) bool {
+	expected := ExpectedAnswer(problem)
+	if submitted.IsZero() || expected.IsZero() {
+		return false
+	}
+	switch expected.Kind {
+	case models.ValueKindBoolean:
+		return submitted.Kind == models.ValueKindBoolean && submitted.Bool() == expected.Bool()
+	case models.ValueKindList:
+		return sameItems(submitted, expected)
+	case models.ValueKindRelation:
+		return submitted.Kind == models.ValueKindRelation && submitted.Numerator == expected.Numerator
+	}
+	if !submitted.IsNumber() {
+		return false
+	}
+	if tolerance := decimalTolerance(config, problem); tolerance != nil {
+		diff := new(big.Rat).Sub(ratOf(submitted), ratOf(expected))
+		return diff.Abs(diff).Cmp(ratOf(*tolerance)) <= 0
+	}
+	if ratOf(submitted).Cmp(ratOf(expected)) != 0 {
+		return false
+	}
+	if config.Fractions != nil && config.Fractions.RequireSimplestForm {
+		return isSimplest(submitted)
+	}
+	return true
+}
+
+// Scorer works out the points an answer earns from the submitted value and
+// the true answer.
+type Scorer func(config models.GameConfig, submitted, expected models.Value) int
+
+// scorers holds the methods that award points by closeness rather than a
+// single point for a correct answer.
+var scorers = map[models.GameConfigMethod]Scorer{
+	models.GameConfigMethodEstimate: scoreEstimate,
+	models.GameConfigMethodTarget:   scoreTarget,
+}
+
+// ScoreAnswer returns the points submitted earns for problem, zero if it
+// earns none.
+func ScoreAnswer(config models.GameConfig, problem models.GameProblem, submitted models.Value) int {
+	if scorer, ok := scorers[problem.Method]; ok {
+		return scorer(config, submitted, ExpectedAnswer(problem))
+	}
+	if CheckAnswer(config, problem, submitted) {
+		return 1
+	}
+	return 0
+}
+
+// checkAnswerFormat rejects submitted if it can't be an answer to problem
+// whatever its value, such as a number given for a comparison.
+func checkAnswerFormat(problem models.GameProblem, submitted models.Value) *Rejection {
+	switch ExpectedAnswer(problem).Kind {
+	case models.ValueKindRelation:
+		if submitted.Kind != models.ValueKindRelation {
+			return reject(RejectionWrongKind, "answer", "answer must be <, > or =")
+		}
+	case models.ValueKindBoolean:
+		if submitted.Kind != models.ValueKindBoolean {
+			return reject(RejectionWrongKind, "answer", "answer must be yes or no")
+		}
+	case models.ValueKindList:
+		if submitted.Kind != models.ValueKindList && !submitted.IsNumber() {
+			return reject(RejectionWrongKind, "answer", "answer must be a list of numbers")
+		}
+	default:
+		if !submitted.IsNumber() {
+			return reject(RejectionWrongKind, "answer", "answer must be a number")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/FiveEightyEight/mwfapi/models"
+)
+
+func TestCheckAnswer(t *testing.T) {
+	add := models.GameProblem{Number1: 2, Number2: 3, Method: models.GameConfigMethodAdd, Answer: models.IntegerValue(5)}
+	half := models.GameProblem{
+		Method:    models.GameConfigMethodFraction,
+		Operation: models.GameConfigMethodAdd,
+		Operands:  []models.Value{models.FractionValue(1, 4), models.FractionValue(1, 4)},
+		Answer:    models.FractionValue(1, 2),
+	}
+	decimal := models.GameProblem{
+		Method:    models.GameConfigMethodDecimal,
+		Operation: models.GameConfigMethodDivide,
+		Operands:  []models.Value{models.DecimalValue(100, 1), models.IntegerValue(3)},
+		Answer:    models.DecimalValue(333, 2),
+	}
+	prime := models.GameProblem{Number1: 12, Method: models.GameConfigMethodPrimeFactors,
+		Answer: models.ListValue(models.IntegerValue(2), models.IntegerValue(2), models.IntegerValue(3))}
+
+	tolerance := models.DecimalValue(1, 2)
+	tolerant := models.GameConfig{Decimals: &models.GameConfigDecimals{Tolerance: &tolerance}}
+	simplest := models.GameConfig{Fractions: &models.GameConfigFractions{RequireSimplestForm: true}}
+
+	tests := []struct {
+		name      string
+		config    models.GameConfig
+		problem   models.GameProblem
+		submitted models.Value
+		want      bool
+	}{
+		{"right", models.GameConfig{}, add, models.IntegerValue(5), true},
+		{"wrong", models.GameConfig{}, add, models.IntegerValue(6), false},
+		{"decimal equal to integer", models.GameConfig{}, add, models.DecimalValue(50, 1), true},
+		{"near miss is not rounded", models.GameConfig{}, add, models.DecimalValue(49, 1), false},
+		{"missing", models.GameConfig{}, add, models.Value{}, false},
+		{"boolean for a number", models.GameConfig{}, add, models.BooleanValue(true), false},
+		{"equivalent fraction", models.GameConfig{}, half, models.FractionValue(2, 4), true},
+		{"equivalent decimal", models.GameConfig{}, half, models.DecimalValue(5, 1), true},
+		{"simplest form required", simplest, half, models.FractionValue(2, 4), false},
+		{"simplest form given", simplest, half, models.FractionValue(1, 2), true},
+		{"exact without tolerance", models.GameConfig{}, decimal, models.DecimalValue(334, 2), false},
+		{"within tolerance", tolerant, decimal, models.DecimalValue(334, 2), true},
+		{"at tolerance", tolerant, decimal, models.DecimalValue(332, 2), true},
+		{"outside tolerance", tolerant, decimal, models.DecimalValue(335, 2), false},
+		{"tolerance only for decimal problems", tolerant, add, models.DecimalValue(501, 2), false},
+		{"list in any order", models.GameConfig{}, prime, models.ListValue(models.IntegerValue(3), models.IntegerValue(2), models.IntegerValue(2)), true},
+		{"list missing a factor", models.GameConfig{}, prime, models.ListValue(models.IntegerValue(2), models.IntegerValue(3)), false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := CheckAnswer(test.config, test.problem, test.submitted); got != test.want {
+				t.Errorf("CheckAnswer(%s) = %v, want %v", test.submitted, got, test.want)
+			}
+		})
+	}
+}
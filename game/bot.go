@@ -0,0 +1,78 @@
+package game
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/FiveEightyEight/mwfapi/models"
+)
+
+const minBotResponseTime = 500 * time.Millisecond
+
+// BotSkills are the preset profiles a host can pick from instead of tuning a bot by hand.
+var BotSkills = map[string]models.BotProfile{
+	"easy": {
+		Accuracy:     0.6,
+		ResponseTime: models.BotResponseTime{MeanMs: 9000, StdDevMs: 3000},
+		Errors:       models.BotErrorProfile{OffByOne: 0.5, WrongOperation: 0.3, DigitSwap: 0.2},
+	},
+	"medium": {
+		Accuracy:     0.8,
+		ResponseTime: models.BotResponseTime{MeanMs: 6000, StdDevMs: 2000},
+		Errors:       models.BotErrorProfile{OffByOne: 0.6, WrongOperation: 0.2, DigitSwap: 0.2},
+	},
+	"hard": {
+		Accuracy:     0.95,
+		ResponseTime: models.BotResponseTime{MeanMs: 3500, StdDevMs: 1000},
+		Errors:       models.BotErrorProfile{OffByOne: 0.8, WrongOperation: 0.1, DigitSwap: 0.1},
+	},
+}
+
+func ValidateBotProfile(profile models.BotProfile) bool {
+	if profile.Accuracy < 0 || profile.Accuracy > 1 {
+		return false
+	}
+	if profile.ResponseTime.MeanMs <= 0 || profile.ResponseTime.StdDevMs < 0 {
+		return false
+	}
+	mistakes := profile.Errors
+	return mistakes.OffByOne >= 0 && mistakes.WrongOperation >= 0 && mistakes.DigitSwap >= 0
+}
+
+// BotAnswer returns the answer a bot with the given profile submits for problem.
+// With probability Accuracy it is correct, otherwise it is a mistake picked
+// according to the profile's error weights.
+func BotAnswer(problem models.GameProblem, profile models.BotProfile, random *rand.Rand) models.Value {
+	expected := ExpectedAnswer(problem)
+	if random.Float64() < profile.Accuracy {
+		return expected
+	}
+
+	mistakes := profile.Errors
+	total := mistakes.OffByOne + mistakes.WrongOperation + mistakes.DigitSwap
+	if total <= 0 {
+		return offByOne(expected, random)
+	}
+
+	pick := random.Float64() * total
+	switch {
+	case pick < mistakes.OffByOne:
+		return offByOne(expected, random)
+	case pick < mistakes.OffByOne+mistakes.WrongOperation && problem.Blank != models.GameProblemSlotNumber1 && problem.Blank != models.GameProblemSlotNumber2:
+		return wrongOperation(problem, random)
+	case pick < mistakes.OffByOne+mistakes.WrongOperation:
+		return offByOne(expected, random)
+	default:
+		return swapDigits(expected, random)
+	}
+}
+
+// BotResponseDelay samples how long a bot takes to answer a problem.
+func BotResponseDelay(profile models.BotProfile, random *rand.Rand) time.Duration {
+	ms := random.NormFloat64()*float64(profile.ResponseTime.StdDevMs) + float64(profile.ResponseTime.MeanMs)
+	delay := time.Duration(ms) * time.Millisecond
+	if delay < minBotResponseTime {
+		return minBotResponseTime
+	}
+	return delay
+}
@@ -0,0 +1,77 @@
+package game
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/FiveEightyEight/mwfapi/models"
+)
+
+func TestValidateBotProfile(t *testing.T) {
+	valid := BotSkills["medium"]
+	tuned := func(change func(*models.BotProfile)) models.BotProfile {
+		profile := valid
+		change(&profile)
+		return profile
+	}
+
+	tests := []struct {
+		name    string
+		profile models.BotProfile
+		valid   bool
+	}{
+		{"preset", valid, true},
+		{"perfect", tuned(func(p *models.BotProfile) { p.Accuracy = 1 }), true},
+		{"accuracy above 1", tuned(func(p *models.BotProfile) { p.Accuracy = 1.1 }), false},
+		{"negative accuracy", tuned(func(p *models.BotProfile) { p.Accuracy = -0.1 }), false},
+		{"no response time", tuned(func(p *models.BotProfile) { p.ResponseTime.MeanMs = 0 }), false},
+		{"negative spread", tuned(func(p *models.BotProfile) { p.ResponseTime.StdDevMs = -1 }), false},
+		{"negative error weight", tuned(func(p *models.BotProfile) { p.Errors.DigitSwap = -1 }), false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := ValidateBotProfile(test.profile); got != test.valid {
+				t.Errorf("expected %v, got %v", test.valid, got)
+			}
+		})
+	}
+}
+
+func TestBotAnswer(t *testing.T) {
+	config := models.GameConfig{
+		Methods: []models.GameConfigMethod{models.GameConfigMethodAdd, models.GameConfigMethodSubtract, models.GameConfigMethodMultiply},
+		Range:   models.GameConfigRange{Min: 2, Max: 99},
+	}
+	problems := GenerateGameProblems(config, 3)
+	random := rand.New(rand.NewSource(1))
+
+	perfect := BotSkills["hard"]
+	perfect.Accuracy = 1
+	hopeless := BotSkills["easy"]
+	hopeless.Accuracy = 0
+	for _, problem := range problems {
+		if answer := BotAnswer(problem, perfect, random); !CheckAnswer(config, problem, answer) {
+			t.Errorf("%s: a perfect bot answered %s", problem.Text, answer)
+		}
+		if answer := BotAnswer(problem, hopeless, random); CheckAnswer(config, problem, answer) {
+			t.Errorf("%s: a bot that never gets it right answered %s", problem.Text, answer)
+		}
+	}
+}
+
+func TestBotResponseDelay(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	quick := models.BotProfile{ResponseTime: models.BotResponseTime{MeanMs: 1, StdDevMs: 1000}}
+	for i := 0; i < 100; i++ {
+		if delay := BotResponseDelay(quick, random); delay < minBotResponseTime {
+			t.Fatalf("expected at least %v, got %v", minBotResponseTime, delay)
+		}
+	}
+
+	steady := models.BotProfile{ResponseTime: models.BotResponseTime{MeanMs: 4000}}
+	if delay := BotResponseDelay(steady, random); delay != 4*time.Second {
+		t.Errorf("expected exactly the mean without spread, got %v", delay)
+	}
+}
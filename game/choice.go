@@ -0,0 +1,162 @@
+package game
+
+import (
+	"math/rand"
+
+	"github.com/FiveEightyEight/mwfapi/models"
+)
+
+const (
+	ChoiceCount = 4
+
+	distractorAttempts = 20
+)
+
+var choiceIDs = []string{"a", "b", "c", "d"}
+
+// sameValue reports whether a and b are the same answer, comparing numbers
+// by value so 1/2 and 0.5 count as one.
+func sameValue(a, b models.Value) bool {
+	if a.IsNumber() && b.IsNumber() {
+		return ratOf(a).Cmp(ratOf(b)) == 0
+	}
+	return a.Kind == b.Kind && a.String() == b.String()
+}
+
+// addChoices gives problem ChoiceCount options, the answer and distractors
+// made from common mistakes, in random order. A distractor that config would
+// accept as an answer, such as another factor pair or an estimate close
+// enough to score, is left out. Answers with fewer possible values, such as
+// yes or no, get fewer options.
+func addChoices(config models.GameConfig, problem *models.GameProblem, random *rand.Rand) {
+	expected := ExpectedAnswer(*problem)
+	options := []models.Value{expected}
+	addOption := func(option models.Value) {
+		if option.IsZero() || len(options) == ChoiceCount {
+			return
+		}
+		if option.Kind == models.ValueKindFraction && option.Denominator == 1 {
+			option = models.IntegerValue(option.Int())
+		}
+		for _, existing := range options {
+			if sameValue(existing, option) {
+				return
+			}
+		}
+		if points, _ := ValidateSubmission(config, *problem, models.Submission{Answer: option}); points > 0 {
+			return
+		}
+		options = append(options, option)
+	}
+
+	switch expected.Kind {
+	case models.ValueKindBoolean:
+		addOption(models.BooleanValue(!expected.Bool()))
+	case models.ValueKindRelation:
+		for cmp := -1; cmp <= 1; cmp++ {
+			addOption(models.RelationValue(cmp))
+		}
+	default:
+		answerBlank := problem.Blank == "" || problem.Blank == models.GameProblemSlotAnswer
+		for attempt := 0; attempt < distractorAttempts && len(options) < ChoiceCount; attempt++ {
+			switch attempt % 4 {
+			case 0:
+				if answerBlank {
+					addOption(wrongOperation(*problem, random))
+				}
+			case 1:
+				if answerBlank {
+					if mistake, ok := carryMistake(*problem); ok {
+						addOption(mistake)
+					}
+				}
+			case 2:
+				addOption(swapDigits(expected, random))
+			case 3:
+				addOption(offByOne(expected, random))
+			}
+		}
+		// Fall back to values close to the answer, or for a factorization
+		// to ones with two factors multiplied together or one repeated
+		if expected.Kind == models.ValueKindList {
+			items := expected.Items
+			for i := 0; i+1 < len(items); i++ {
+				merged := append(append([]models.Value{}, items[:i]...), models.IntegerValue(items[i].Int()*items[i+1].Int()))
+				addOption(models.ListValue(append(merged, items[i+2:]...)...))
+			}
+			for i := range items {
+				addOption(models.ListValue(append(append([]models.Value{}, items[:i+1]...), items[i:]...)...))
+			}
+		}
+		for step := int64(2); len(options) < ChoiceCount && expected.IsNumber() && step < 2*ChoiceCount; step++ {
+			nudged := expected
+			nudged.Mixed = false
+			nudged.Numerator += step / 2 * (1 - 2*(step%2))
+			if nudged.Kind != models.ValueKindFraction || nudged.Numerator != 0 {
+				addOption(nudged)
+			}
+		}
+	}
+
+	random.Shuffle(len(options), func(i, j int) {
+		options[i], options[j] = options[j], options[i]
+	})
+	problem.Choices = make([]models.Choice, len(options))
+	for i, option := range options {
+		problem.Choices[i] = models.Choice{ID: choiceIDs[i], Value: option}
+	}
+}
+
+// carryMistake adds or subtracts column by column without carrying or
+// borrowing, e.g. 47 + 38 = 75 or 52 - 38 = 26.
+func carryMistake(problem models.GameProblem) (models.Value, bool) {
+	if problem.Number1 < 0 || problem.Number2 < 0 || problem.Answer.Kind != models.ValueKindInteger {
+		return models.Value{}, false
+	}
+	var column func(a, b int) int
+	switch problem.Method {
+	case models.GameConfigMethodAdd:
+		column = func(a, b int) int { return (a + b) % 10 }
+	case models.GameConfigMethodSubtract:
+		column = func(a, b int) int { return abs(a - b) }
+	default:
+		return models.Value{}, false
+	}
+
+	result, place := 0, 1
+	for a, b := problem.Number1, problem.Number2; a > 0 || b > 0; a, b = a/10, b/10 {
+		result += column(a%10, b%10) * place
+		place *= 10
+	}
+	return models.IntegerValue(result), result != problem.Answer.Int()
+}
+
+// ChoiceAnswer returns the value of the choice with the given ID.
+func ChoiceAnswer(problem models.GameProblem, id string) (models.Value, bool) {
+	for _, choice := range problem.Choices {
+		if choice.ID == id {
+			return choice.Value, true
+		}
+	}
+	return models.Value{}, false
+}
+
+// BotChoice picks the choice a bot with the given profile submits for
+// problem, a random wrong one if its mistake isn't among them.
+func BotChoice(problem models.GameProblem, profile models.BotProfile, random *rand.Rand) string {
+	answer := BotAnswer(problem, profile, random)
+	expected := ExpectedAnswer(problem)
+	var wrong []string
+	for _, choice := range problem.Choices {
+		if sameValue(choice.Value, answer) {
+			return choice.ID
+		}
+		if !sameValue(choice.Value, expected) {
+			wrong = append(wrong, choice.ID)
+		}
+	}
+	if len(wrong) == 0 {
+		return problem.Choices[0].ID
+	}
+	return wrong[random.Intn(len(wrong))]
+}
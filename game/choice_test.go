@@ -0,0 +1,85 @@
+package game
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/FiveEightyEight/mwfapi/models"
+)
+
+func TestAddChoices(t *testing.T) {
+	config := func(min, max int, methods ...models.GameConfigMethod) models.GameConfig {
+		return models.GameConfig{
+			Methods:        methods,
+			Range:          models.GameConfigRange{Min: min, Max: max},
+			MultipleChoice: true,
+		}
+	}
+
+	tests := []struct {
+		name   string
+		config models.GameConfig
+	}{
+		{"basic", config(1, 50, basicMethods...)},
+		{"factor pair", config(4, 30, models.GameConfigMethodFactorPair)},
+		{"estimate", config(10, 99, models.GameConfigMethodEstimate)},
+		{"fraction and decimal", config(1, 12, models.GameConfigMethodFraction, models.GameConfigMethodDecimal)},
+		{"number theory", config(2, 60, models.GameConfigMethodIsPrime, models.GameConfigMethodPrimeFactors, models.GameConfigMethodGCD)},
+		{"compare", config(1, 20, models.GameConfigMethodCompare)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for seed := int64(0); seed < 30; seed++ {
+				for _, problem := range GenerateGameProblems(test.config, seed) {
+					if len(problem.Choices) < 2 {
+						t.Fatalf("seed %d: %s has %d choices", seed, problem.Text, len(problem.Choices))
+					}
+					right := 0
+					for _, choice := range problem.Choices {
+						if points, _ := ValidateSubmission(test.config, problem, models.Submission{Choice: choice.ID}); points > 0 {
+							right++
+						}
+					}
+					if right != 1 {
+						t.Fatalf("seed %d: %s has %d choices that score, expected 1: %v", seed, problem.Text, right, problem.Choices)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestAddChoicesFactorPair(t *testing.T) {
+	// 2 + 2 is also 2 × 2, so that mistake can't be offered as a wrong choice
+	problem := factorPairProblem(4)
+	config := models.GameConfig{Methods: []models.GameConfigMethod{models.GameConfigMethodFactorPair}, MultipleChoice: true}
+	addChoices(config, &problem, rand.New(rand.NewSource(1)))
+	for _, choice := range problem.Choices {
+		if sameItems(choice.Value, factorList([]int{2, 2})) {
+			continue
+		}
+		if points, _ := ValidateSubmission(config, problem, models.Submission{Choice: choice.ID}); points > 0 {
+			t.Errorf("expected %s to be wrong", choice.Value)
+		}
+	}
+}
+
+func TestBotChoice(t *testing.T) {
+	config := models.GameConfig{
+		Methods:        []models.GameConfigMethod{models.GameConfigMethodMultiply},
+		Range:          models.GameConfigRange{Min: 2, Max: 12},
+		MultipleChoice: true,
+	}
+	random := rand.New(rand.NewSource(1))
+	perfect, hopeless := BotSkills["hard"], BotSkills["easy"]
+	perfect.Accuracy, hopeless.Accuracy = 1, 0
+	for _, problem := range GenerateGameProblems(config, 1) {
+		if points, _ := ValidateSubmission(config, problem, models.Submission{Choice: BotChoice(problem, perfect, random)}); points == 0 {
+			t.Errorf("%s: expected a perfect bot to pick the answer", problem.Text)
+		}
+		if points, _ := ValidateSubmission(config, problem, models.Submission{Choice: BotChoice(problem, hopeless, random)}); points > 0 {
+			t.Errorf("%s: expected a bot that never gets it right to pick a wrong choice", problem.Text)
+		}
+	}
+}
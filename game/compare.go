@@ -0,0 +1,162 @@
+package game
+
+import (
+	"math/rand"
+
+	"github.com/FiveEightyEight/mwfapi/models"
+)
+
+const (
+	compareTries  = 10
+	maxCompareGap = 3
+)
+
+func comparisonOperations(config models.GameConfig) []models.GameConfigMethod {
+	if config.Comparisons == nil || len(config.Comparisons.Operations) == 0 {
+		return basicMethods
+	}
+	return config.Comparisons.Operations
+}
+
+func binaryExpression(operator models.GameConfigMethod, a, b int) *models.Expression {
+	return &models.Expression{Operator: operator, Left: leafExpression(a), Right: leafExpression(b)}
+}
+
+func leafExpression(n int) *models.Expression {
+	return &models.Expression{Value: &n}
+}
+
+// randomSide builds a single operation over operands from the config's range.
+// Subtraction stays non-negative and division exact.
+func randomSide(config models.GameConfig, random *rand.Rand) *models.Expression {
+	operations := comparisonOperations(config)
+	operator := operations[random.Intn(len(operations))]
+	a, b := randomIn(config.Range, random), randomIn(config.Range, random)
+	switch operator {
+	case models.GameConfigMethodSubtract:
+		if a < b {
+			a, b = b, a
+		}
+	case models.GameConfigMethodDivide:
+		if b == 0 {
+			b = 1
+		}
+		a *= b
+	}
+	return binaryExpression(operator, a, b)
+}
+
+// sideWithValue builds an operation over operands from the config's range
+// that comes to target, other than the same operation as other. It picks an
+// operation, then its operands, at random among those that can reach target,
+// so a target reachable only from its divisors is found as readily as any
+// other. Negative targets are only reached when the range allows negative
+// operands.
+func sideWithValue(config models.GameConfig, target int, other *models.Expression, random *rand.Rand) (*models.Expression, bool) {
+	r := config.Range
+	if target < 0 && r.Min >= 0 {
+		return nil, false
+	}
+
+	type candidates struct {
+		operator models.GameConfigMethod
+		operands []int
+	}
+	var options []candidates
+	for _, operator := range comparisonOperations(config) {
+		option := candidates{operator: operator}
+		for b := r.Min; b <= r.Max; b++ {
+			a, ok := leftOperand(operator, target, b)
+			if !ok || a < r.Min || a > r.Max || sameOperation(other, binaryExpression(operator, a, b)) {
+				continue
+			}
+			option.operands = append(option.operands, b)
+		}
+		if len(option.operands) > 0 {
+			options = append(options, option)
+		}
+	}
+	if len(options) == 0 {
+		return nil, false
+	}
+
+	option := options[random.Intn(len(options))]
+	b := option.operands[random.Intn(len(option.operands))]
+	a, _ := leftOperand(option.operator, target, b)
+	return binaryExpression(option.operator, a, b), true
+}
+
+// leftOperand is the a for which a operator b comes to target, reporting
+// false if there is none.
+func leftOperand(operator models.GameConfigMethod, target, b int) (int, bool) {
+	switch operator {
+	case models.GameConfigMethodAdd:
+		return target - b, true
+	case models.GameConfigMethodSubtract:
+		return target + b, true
+	case models.GameConfigMethodMultiply:
+		if b == 0 || target%b != 0 {
+			return 0, false
+		}
+		return target / b, true
+	case models.GameConfigMethodDivide:
+		if b == 0 {
+			return 0, false
+		}
+		return target * b, true
+	}
+	return 0, false
+}
+
+// sameOperation reports whether two sides are the same operation on the same
+// operands, which would make them trivially equal.
+func sameOperation(x, y *models.Expression) bool {
+	if x.Operator != y.Operator {
+		return false
+	}
+	if *x.Left.Value == *y.Left.Value && *x.Right.Value == *y.Right.Value {
+		return true
+	}
+	commutative := x.Operator == models.GameConfigMethodAdd || x.Operator == models.GameConfigMethodMultiply
+	return commutative && *x.Left.Value == *y.Right.Value && *x.Right.Value == *y.Left.Value
+}
+
+// generateCompareProblem shows two operations side by side and asks whether
+// the left is less than, equal to or greater than the right. About a third
+// of the pairs are equal and the rest are a near miss, like 7 × 8 and 6 × 9,
+// so the answer can't be told at a glance.
+func generateCompareProblem(config models.GameConfig, random *rand.Rand) models.GameProblem {
+	equal := random.Intn(3) == 0
+	gap := 0
+	if !equal {
+		gap = random.Intn(maxCompareGap) + 1
+		if random.Intn(2) == 0 {
+			gap = -gap
+		}
+	}
+
+	// Some left sides have no partner at the wanted value, so try a few
+	var left, right *models.Expression
+	var leftValue int
+	for try := 0; try < compareTries && right == nil; try++ {
+		left = randomSide(config, random)
+		leftValue, _ = EvaluateExpression(left)
+		right, _ = sideWithValue(config, leftValue+gap, left, random)
+	}
+	if right == nil {
+		right = randomSide(config, random)
+	}
+	rightValue, _ := EvaluateExpression(right)
+
+	if random.Intn(2) == 0 {
+		left, right = right, left
+		leftValue, rightValue = rightValue, leftValue
+	}
+
+	return models.GameProblem{
+		Method: models.GameConfigMethodCompare,
+		Answer: models.RelationValue(leftValue - rightValue),
+		Sides:  []*models.Expression{left, right},
+		Text:   RenderExpression(left) + " ? " + RenderExpression(right),
+	}
+}
@@ -0,0 +1,41 @@
+package game
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/FiveEightyEight/mwfapi/models"
+)
+
+func TestGenerateCompareProblem(t *testing.T) {
+	const problems = 600
+	for _, operation := range basicMethods {
+		for _, max := range []int{12, 1000} {
+			config := models.GameConfig{
+				Methods:     []models.GameConfigMethod{models.GameConfigMethodCompare},
+				Range:       models.GameConfigRange{Min: 1, Max: max},
+				Comparisons: &models.GameConfigComparisons{Operations: []models.GameConfigMethod{operation}},
+			}
+			random := rand.New(rand.NewSource(1))
+			equal := 0
+			for i := 0; i < problems; i++ {
+				problem := generateCompareProblem(config, random)
+				left, _ := EvaluateExpression(problem.Sides[0])
+				right, _ := EvaluateExpression(problem.Sides[1])
+				if problem.Answer.Numerator != models.RelationValue(left-right).Numerator {
+					t.Fatalf("%s: answer %s does not match sides %d and %d", problem.Text, problem.Answer, left, right)
+				}
+				if sameOperation(problem.Sides[0], problem.Sides[1]) {
+					t.Fatalf("%s: sides are trivially equal", problem.Text)
+				}
+				if left == right {
+					equal++
+				}
+			}
+			// About a third should be equal
+			if ratio := float64(equal) / problems; ratio < 0.25 || ratio > 0.42 {
+				t.Errorf("%s up to %d: %.2f of pairs equal, want about a third", operation, max, ratio)
+			}
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package game
+
+import (
+	"hash/fnv"
+	"time"
+
+	"github.com/FiveEightyEight/mwfapi/models"
+)
+
+// DailyChallengeConfig is the config every daily challenge is generated from.
+var DailyChallengeConfig = models.GameConfig{
+	Methods: []models.GameConfigMethod{
+		models.GameConfigMethodAdd,
+		models.GameConfigMethodSubtract,
+		models.GameConfigMethodMultiply,
+		models.GameConfigMethodDivide,
+	},
+	Range:        models.GameConfigRange{Min: 2, Max: 12},
+	ProblemCount: 10,
+}
+
+// DailyChallengeDate returns the calendar day, in UTC, whose challenge is offered at t.
+func DailyChallengeDate(t time.Time) string {
+	return t.UTC().Format(time.DateOnly)
+}
+
+// DailySeed derives the seed for a day's challenge from its date.
+func DailySeed(date string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte("daily:" + date))
+	return int64(h.Sum64() & MaxSeed)
+}
+
+// DailyChallengeProblems returns the problem set shared by everyone on date.
+func DailyChallengeProblems(date string) []models.GameProblem {
+	return GenerateGameProblems(DailyChallengeConfig, DailySeed(date))
+}
@@ -0,0 +1,33 @@
+package game
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDailyChallenge(t *testing.T) {
+	if err := ValidateGameConfig(DailyChallengeConfig); err != nil {
+		t.Fatalf("expected the daily config to be valid: %v", err)
+	}
+
+	// Late evening in New York is already the next day in UTC
+	newYork := time.FixedZone("EDT", -4*60*60)
+	if date := DailyChallengeDate(time.Date(2026, 3, 14, 22, 0, 0, 0, newYork)); date != "2026-03-15" {
+		t.Errorf("expected the UTC date 2026-03-15, got %s", date)
+	}
+
+	problems := DailyChallengeProblems("2026-03-15")
+	if len(problems) != DailyChallengeConfig.ProblemCount {
+		t.Fatalf("expected %d problems, got %d", DailyChallengeConfig.ProblemCount, len(problems))
+	}
+	if !reflect.DeepEqual(problems, DailyChallengeProblems("2026-03-15")) {
+		t.Error("expected everyone to get the same problems on the same day")
+	}
+	if reflect.DeepEqual(problems, DailyChallengeProblems("2026-03-16")) {
+		t.Error("expected another day to get other problems")
+	}
+	if seed := DailySeed("2026-03-15"); seed < 0 || seed > MaxSeed {
+		t.Errorf("expected a seed from 0 to %d, got %d", MaxSeed, seed)
+	}
+}
@@ -0,0 +1,163 @@
+package game
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+
+	"github.com/FiveEightyEight/mwfapi/models"
+)
+
+const (
+	DefaultDecimalPlaces = 1
+	MaxDecimalPlaces     = 3
+
+	percentAttempts = 20
+	percentStep     = 5
+)
+
+func decimalSettings(config models.GameConfig) models.GameConfigDecimals {
+	var settings models.GameConfigDecimals
+	if config.Decimals != nil {
+		settings = *config.Decimals
+	}
+	if len(settings.Operations) == 0 {
+		settings.Operations = basicMethods
+	}
+	if settings.Places == 0 {
+		settings.Places = DefaultDecimalPlaces
+	}
+	return settings
+}
+
+// decimalOfRat writes r as a decimal with at most maxPlaces digits after the
+// point. It reports false if r doesn't terminate within that many digits.
+func decimalOfRat(r *big.Rat, maxPlaces int) (models.Value, bool) {
+	scaled := new(big.Rat).Set(r)
+	ten := big.NewRat(10, 1)
+	for places := 0; places <= maxPlaces; places++ {
+		if scaled.IsInt() {
+			return models.DecimalValue(scaled.Num().Int64(), places), true
+		}
+		scaled.Mul(scaled, ten)
+	}
+	return models.Value{}, false
+}
+
+// generateDecimalProblem builds an operation over decimals drawn from the
+// config's range. Division is built from its quotient so it comes out exact.
+func generateDecimalProblem(config models.GameConfig, random *rand.Rand) models.GameProblem {
+	settings := decimalSettings(config)
+	operation := settings.Operations[random.Intn(len(settings.Operations))]
+	scale := 1
+	for i := 0; i < settings.Places; i++ {
+		scale *= 10
+	}
+	randomUnits := func() int64 {
+		return int64(random.Intn((config.Range.Max-config.Range.Min)*scale+1) + config.Range.Min*scale)
+	}
+
+	a, b := randomUnits(), randomUnits()
+	var operand1, operand2, answer models.Value
+	switch operation {
+	case models.GameConfigMethodMultiply:
+		operand1, operand2 = models.DecimalValue(a, settings.Places), models.DecimalValue(b, settings.Places)
+		answer = models.DecimalValue(a*b, settings.Places*2)
+	case models.GameConfigMethodDivide:
+		divisor := int64(random.Intn(config.Range.Max-config.Range.Min+1) + config.Range.Min)
+		if divisor == 0 {
+			divisor = 1
+		}
+		operand1, operand2 = models.DecimalValue(a*divisor, settings.Places), models.IntegerValue(int(divisor))
+		answer = models.DecimalValue(a, settings.Places)
+	default:
+		if operation == models.GameConfigMethodSubtract && a < b {
+			a, b = b, a
+		}
+		operand1, operand2 = models.DecimalValue(a, settings.Places), models.DecimalValue(b, settings.Places)
+		result, _ := applyMethod(operation, int(a), int(b))
+		answer = models.DecimalValue(int64(result), settings.Places)
+	}
+
+	return models.GameProblem{
+		Method:    models.GameConfigMethodDecimal,
+		Operation: operation,
+		Operands:  []models.Value{operand1, operand2},
+		Answer:    answer,
+		Text:      renderOperation(operation, operand1, operand2),
+	}
+}
+
+// generatePercentOfProblem builds "15% of 80", preferring answers that need
+// no more decimal places than the config allows.
+func generatePercentOfProblem(config models.GameConfig, random *rand.Rand) models.GameProblem {
+	settings := decimalSettings(config)
+
+	var percent, base int
+	var answer models.Value
+	for attempt := 0; attempt < percentAttempts; attempt++ {
+		percent = randomPercent(random)
+		base = randomPositive(config.Range, random)
+		var ok bool
+		answer, ok = decimalOfRat(big.NewRat(int64(percent*base), 100), settings.Places)
+		if ok {
+			break
+		}
+	}
+	if answer.IsZero() {
+		// A whole percent of a whole number never needs more than two places
+		answer, _ = decimalOfRat(big.NewRat(int64(percent*base), 100), 2)
+	}
+
+	return models.GameProblem{
+		Method:   models.GameConfigMethodPercentOf,
+		Operands: []models.Value{models.IntegerValue(percent), models.IntegerValue(base)},
+		Answer:   answer,
+		Text:     fmt.Sprintf("%d%% of %d", percent, base),
+	}
+}
+
+// generatePercentRatioProblem builds "What percent of 48 is 12?" with a whole
+// number percentage as the answer.
+func generatePercentRatioProblem(config models.GameConfig, random *rand.Rand) models.GameProblem {
+	percent := randomPercent(random)
+
+	// The whole must be a multiple of this for the part to be a whole number
+	step := 100 / int(new(big.Int).GCD(nil, nil, big.NewInt(int64(percent)), big.NewInt(100)).Int64())
+	low := (max(config.Range.Min, 1) + step - 1) / step
+	high := config.Range.Max / step
+	whole := step
+	if high >= low {
+		whole = step * (random.Intn(high-low+1) + low)
+	}
+	part := whole * percent / 100
+
+	return models.GameProblem{
+		Method:   models.GameConfigMethodPercentRatio,
+		Operands: []models.Value{models.IntegerValue(part), models.IntegerValue(whole)},
+		Answer:   models.IntegerValue(percent),
+		Text:     fmt.Sprintf("What percent of %d is %d?", whole, part),
+	}
+}
+
+func randomPercent(random *rand.Rand) int {
+	return percentStep * (random.Intn(100/percentStep) + 1)
+}
+
+func randomPositive(r models.GameConfigRange, random *rand.Rand) int {
+	low := max(r.Min, 1)
+	return random.Intn(r.Max-low+1) + low
+}
+
+// decimalTolerance returns how far off an answer to problem may be, or nil
+// if it has to be exact.
+func decimalTolerance(config models.GameConfig, problem models.GameProblem) *models.Value {
+	if config.Decimals == nil || config.Decimals.Tolerance == nil {
+		return nil
+	}
+	switch problem.Method {
+	case models.GameConfigMethodDecimal, models.GameConfigMethodPercentOf, models.GameConfigMethodPercentRatio:
+		return config.Decimals.Tolerance
+	}
+	return nil
+}
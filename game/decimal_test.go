@@ -0,0 +1,60 @@
+package game
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/FiveEightyEight/mwfapi/models"
+)
+
+func TestGenerateDecimalProblems(t *testing.T) {
+	config := models.GameConfig{
+		Methods: []models.GameConfigMethod{
+			models.GameConfigMethodDecimal,
+			models.GameConfigMethodPercentOf,
+			models.GameConfigMethodPercentRatio,
+		},
+		Range:    models.GameConfigRange{Min: 1, Max: 100},
+		Decimals: &models.GameConfigDecimals{Places: 2},
+	}
+	for seed := int64(0); seed < 50; seed++ {
+		for _, problem := range GenerateGameProblems(config, seed) {
+			a, b := ratOf(problem.Operands[0]), ratOf(problem.Operands[1])
+			var want *big.Rat
+			switch problem.Method {
+			case models.GameConfigMethodDecimal:
+				want, _ = applyRat(problem.Operation, a, b)
+				if want.Sign() < 0 {
+					t.Fatalf("seed %d: %s goes below zero", seed, problem.Text)
+				}
+			case models.GameConfigMethodPercentOf:
+				want = new(big.Rat).Mul(a, b)
+				want.Quo(want, big.NewRat(100, 1))
+			case models.GameConfigMethodPercentRatio:
+				want = new(big.Rat).Quo(a, b)
+				want.Mul(want, big.NewRat(100, 1))
+				if !a.IsInt() || !want.IsInt() {
+					t.Fatalf("seed %d: %s should have a whole part and percent", seed, problem.Text)
+				}
+			}
+			if ratOf(problem.Answer).Cmp(want) != 0 {
+				t.Fatalf("seed %d: %s should be %s, got %s", seed, problem.Text, want.FloatString(4), problem.Answer)
+			}
+			if problem.Answer.Kind == models.ValueKindDecimal && problem.Answer.Denominator > 10000 {
+				t.Fatalf("seed %d: %s has more than 4 places, got %s", seed, problem.Text, problem.Answer)
+			}
+		}
+	}
+}
+
+func TestDecimalOfRat(t *testing.T) {
+	if value, ok := decimalOfRat(big.NewRat(3, 8), 3); !ok || value.Numerator != 375 || value.Places() != 3 {
+		t.Errorf("expected 0.375, got %s, %v", value, ok)
+	}
+	if _, ok := decimalOfRat(big.NewRat(3, 8), 2); ok {
+		t.Error("expected 0.375 not to fit in 2 places")
+	}
+	if _, ok := decimalOfRat(big.NewRat(1, 3), MaxDecimalPlaces); ok {
+		t.Error("expected a third never to terminate")
+	}
+}
@@ -0,0 +1,364 @@
+package game
+
+import (
+	"math"
+	"sort"
+
+	"github.com/FiveEightyEight/mwfapi/models"
+)
+
+const (
+	// missPenalty is how much harder a wrong answer makes a problem look
+	// than a right one, in the units of ObservedDifficulty.
+	missPenalty = 2
+	// maxSampleMs caps the time of a sample, so an answer left while the
+	// player was away doesn't count as a very hard problem.
+	maxSampleMs = 120000
+	// calibrationStrength is how many samples' worth of weight the current
+	// weights carry when refitting, so a few samples can't swing them far.
+	calibrationStrength = 10
+)
+
+// DifficultyModel rates problems as a weighted sum of their features, named
+// as ProblemFeatures names them. Features without a weight count for nothing.
+type DifficultyModel struct {
+	Weights map[string]float64 `json:"weights"`
+}
+
+// DefaultDifficultyModel is fitted by hand to roughly match
+// ObservedDifficulty, about log₂ of the seconds a problem takes.
+var DefaultDifficultyModel = DifficultyModel{Weights: map[string]float64{
+	"bias":             1.0,
+	"digits":           0.2,
+	"magnitude":        0.1,
+	"answer_digits":    0.1,
+	"carries":          0.4,
+	"partial_products": 0.3,
+	"steps":            0.5,
+	"negative":         0.5,
+	"non_integer":      0.8,
+	"missing_operand":  0.5,
+	"multiple_choice":  -0.3,
+
+	"method:" + string(models.GameConfigMethodSubtract):     0.3,
+	"method:" + string(models.GameConfigMethodMultiply):     0.8,
+	"method:" + string(models.GameConfigMethodDivide):       1.0,
+	"method:" + string(models.GameConfigMethodExpression):   1.0,
+	"method:" + string(models.GameConfigMethodFraction):     2.0,
+	"method:" + string(models.GameConfigMethodDecimal):      1.5,
+	"method:" + string(models.GameConfigMethodPercentOf):    1.5,
+	"method:" + string(models.GameConfigMethodPercentRatio): 2.0,
+	"method:" + string(models.GameConfigMethodPower):        1.0,
+	"method:" + string(models.GameConfigMethodSquare):       0.5,
+	"method:" + string(models.GameConfigMethodCube):         1.0,
+	"method:" + string(models.GameConfigMethodSquareRoot):   0.8,
+	"method:" + string(models.GameConfigMethodPowerOfTen):   0.5,
+	"method:" + string(models.GameConfigMethodGCD):          1.5,
+	"method:" + string(models.GameConfigMethodLCM):          2.0,
+	"method:" + string(models.GameConfigMethodIsPrime):      1.0,
+	"method:" + string(models.GameConfigMethodPrimeFactors): 2.0,
+	"method:" + string(models.GameConfigMethodFactorPair):   0.8,
+	"method:" + string(models.GameConfigMethodSequence):     1.2,
+	"method:" + string(models.GameConfigMethodCompare):      1.0,
+	"method:" + string(models.GameConfigMethodEstimate):     0.5,
+	"method:" + string(models.GameConfigMethodTarget):       1.5,
+}}
+
+// Difficulty rates problem with the default model.
+func Difficulty(problem models.GameProblem) float64 {
+	return DefaultDifficultyModel.Difficulty(problem)
+}
+
+// Difficulty rates problem, higher being harder. Ratings never go below zero.
+func (m DifficultyModel) Difficulty(problem models.GameProblem) float64 {
+	features := ProblemFeatures(problem)
+	names := make([]string, 0, len(features))
+	for name := range features {
+		names = append(names, name)
+	}
+	// Summed in a fixed order, so a problem always gets the same rating
+	sort.Strings(names)
+	total := 0.0
+	for _, name := range names {
+		total += m.Weights[name] * features[name]
+	}
+	return math.Max(total, 0)
+}
+
+// ProblemFeatures describes what makes problem hard: how many digits its
+// operands and answer have and how large they are, the carries and borrows
+// of a sum or difference, the partial products of a product, the number of
+// steps, and its method, as "method:" followed by the method's name.
+func ProblemFeatures(problem models.GameProblem) map[string]float64 {
+	operands := problemOperands(problem)
+	answer := ExpectedAnswer(problem)
+
+	features := map[string]float64{
+		"bias":                             1,
+		"method:" + string(problem.Method): 1,
+	}
+	largest := 0.0
+	for _, operand := range operands {
+		features["digits"] += float64(valueDigits(operand))
+		largest = math.Max(largest, math.Abs(valueFloat(operand)))
+		if isNegative(operand) {
+			features["negative"] = 1
+		}
+		if operand.Kind == models.ValueKindFraction || operand.Kind == models.ValueKindDecimal {
+			features["non_integer"] = 1
+		}
+	}
+	features["magnitude"] = math.Log10(1 + largest)
+
+	answers := []models.Value{answer}
+	if answer.Kind == models.ValueKindList {
+		answers = answer.Items
+	}
+	for _, value := range answers {
+		if !value.IsNumber() {
+			continue
+		}
+		features["answer_digits"] += float64(valueDigits(value))
+		if isNegative(value) {
+			features["negative"] = 1
+		}
+		if value.Kind == models.ValueKindFraction || value.Kind == models.ValueKindDecimal {
+			features["non_integer"] = 1
+		}
+	}
+
+	operation := problem.Method
+	if problem.Operation != "" {
+		operation = problem.Operation
+	}
+	if problem.Expression == nil && len(problem.Operands) == 0 {
+		switch operation {
+		case models.GameConfigMethodAdd, models.GameConfigMethodSubtract:
+			features["carries"] = float64(carries(operation, problem.Number1, problem.Number2))
+		case models.GameConfigMethodMultiply:
+			features["partial_products"] = float64(digitCount(problem.Number1) * digitCount(problem.Number2))
+		}
+	}
+
+	switch {
+	case problem.Expression != nil:
+		features["steps"] = float64(len(expressionLeaves(problem.Expression)) - 1)
+	case len(problem.Sides) > 0:
+		for _, side := range problem.Sides {
+			features["steps"] += float64(len(expressionLeaves(side)) - 1)
+		}
+	case problem.Method == models.GameConfigMethodTarget:
+		features["steps"] = float64(len(problem.Operands) - 1)
+	}
+
+	if problem.Blank == models.GameProblemSlotNumber1 || problem.Blank == models.GameProblemSlotNumber2 {
+		features["missing_operand"] = 1
+	}
+	if len(problem.Choices) > 0 {
+		features["multiple_choice"] = 1
+	}
+	return features
+}
+
+// problemOperands lists the numbers a problem is posed with.
+func problemOperands(problem models.GameProblem) []models.Value {
+	var leaves []int
+	switch {
+	case problem.Expression != nil:
+		leaves = expressionLeaves(problem.Expression)
+	case len(problem.Sides) > 0:
+		for _, side := range problem.Sides {
+			leaves = append(leaves, expressionLeaves(side)...)
+		}
+	case len(problem.Operands) > 0:
+		return problem.Operands
+	case len(problem.Terms) > 0:
+		var terms []models.Value
+		for _, term := range problem.Terms {
+			if !term.IsZero() {
+				terms = append(terms, term)
+			}
+		}
+		return terms
+	default:
+		switch problem.Method {
+		case models.GameConfigMethodIsPrime, models.GameConfigMethodPrimeFactors, models.GameConfigMethodFactorPair:
+			leaves = []int{problem.Number1}
+		default:
+			leaves = []int{problem.Number1, problem.Number2}
+		}
+	}
+	values := make([]models.Value, len(leaves))
+	for i, leaf := range leaves {
+		values[i] = models.IntegerValue(leaf)
+	}
+	return values
+}
+
+func digitCount(n int) int {
+	count := 1
+	for n = abs(n); n >= 10; n /= 10 {
+		count++
+	}
+	return count
+}
+
+// valueDigits counts the digits written to show v, numerator and
+// denominator both for a fraction.
+func valueDigits(v models.Value) int {
+	count := 0
+	for _, r := range v.String() {
+		if r >= '0' && r <= '9' {
+			count++
+		}
+	}
+	return count
+}
+
+func valueFloat(v models.Value) float64 {
+	f, _ := ratOf(v).Float64()
+	return f
+}
+
+func isNegative(v models.Value) bool {
+	return v.IsNumber() && ratOf(v).Sign() < 0
+}
+
+// carries counts the carries of a + b, or the borrows of a - b, done column
+// by column. Only problems over non-negative numbers are counted.
+func carries(operation models.GameConfigMethod, a, b int) int {
+	if a < 0 || b < 0 {
+		return 0
+	}
+	if operation == models.GameConfigMethodSubtract && a < b {
+		a, b = b, a
+	}
+	count, carry := 0, 0
+	for ; a > 0 || b > 0; a, b = a/10, b/10 {
+		var column int
+		if operation == models.GameConfigMethodSubtract {
+			column = a%10 - b%10 - carry
+			carry = 0
+			if column < 0 {
+				carry = 1
+			}
+		} else {
+			column = a%10 + b%10 + carry
+			carry = 0
+			if column >= 10 {
+				carry = 1
+			}
+		}
+		count += carry
+	}
+	return count
+}
+
+// DifficultySamples pairs graded answers, such as a challenge attempt's,
+// with the problems they answer.
+func DifficultySamples(problems []models.GameProblem, answers []models.AttemptAnswer) []models.DifficultySample {
+	samples := make([]models.DifficultySample, 0, len(answers))
+	for i, answer := range answers {
+		if i >= len(problems) || answer.TimeMs <= 0 {
+			continue
+		}
+		samples = append(samples, models.DifficultySample{Problem: problems[i], Correct: answer.Correct, TimeMs: answer.TimeMs})
+	}
+	return samples
+}
+
+// ObservedDifficulty is how hard a sample shows its problem to be: log₂ of
+// one plus the seconds it took, plus missPenalty if it was answered wrong.
+func ObservedDifficulty(sample models.DifficultySample) float64 {
+	seconds := float64(min(sample.TimeMs, maxSampleMs)) / 1000
+	observed := math.Log2(1 + seconds)
+	if !sample.Correct {
+		observed += missPenalty
+	}
+	return observed
+}
+
+// Calibrate refits the model's weights to the observed difficulty of
+// samples by least squares, pulled towards the current weights so features
+// the samples say little about keep their old weight.
+func (m DifficultyModel) Calibrate(samples []models.DifficultySample) DifficultyModel {
+	names := map[string]bool{}
+	for name := range m.Weights {
+		names[name] = true
+	}
+	features := make([]map[string]float64, len(samples))
+	for i, sample := range samples {
+		features[i] = ProblemFeatures(sample.Problem)
+		for name := range features[i] {
+			names[name] = true
+		}
+	}
+	order := make([]string, 0, len(names))
+	for name := range names {
+		order = append(order, name)
+	}
+	sort.Strings(order)
+	index := make(map[string]int, len(order))
+	for i, name := range order {
+		index[name] = i
+	}
+
+	// Solve (XᵀX + λI)w = Xᵀy + λw₀
+	n := len(order)
+	a := make([][]float64, n)
+	b := make([]float64, n)
+	for i, name := range order {
+		a[i] = make([]float64, n)
+		a[i][i] = calibrationStrength
+		b[i] = calibrationStrength * m.Weights[name]
+	}
+	for s, sample := range samples {
+		y := ObservedDifficulty(sample)
+		for name, x := range features[s] {
+			i := index[name]
+			b[i] += x * y
+			for other, z := range features[s] {
+				a[i][index[other]] += x * z
+			}
+		}
+	}
+
+	weights := solveLinear(a, b)
+	fitted := DifficultyModel{Weights: make(map[string]float64, n)}
+	for i, name := range order {
+		fitted.Weights[name] = weights[i]
+	}
+	return fitted
+}
+
+// solveLinear solves ax = b by Gaussian elimination with partial pivoting.
+// a must be non-singular, which the ridge term in Calibrate ensures.
+func solveLinear(a [][]float64, b []float64) []float64 {
+	n := len(b)
+	for col := 0; col < n; col++ {
+		pivot := col
+		for row := col + 1; row < n; row++ {
+			if math.Abs(a[row][col]) > math.Abs(a[pivot][col]) {
+				pivot = row
+			}
+		}
+		a[col], a[pivot] = a[pivot], a[col]
+		b[col], b[pivot] = b[pivot], b[col]
+		for row := col + 1; row < n; row++ {
+			factor := a[row][col] / a[col][col]
+			for k := col; k < n; k++ {
+				a[row][k] -= factor * a[col][k]
+			}
+			b[row] -= factor * b[col]
+		}
+	}
+	x := make([]float64, n)
+	for row := n - 1; row >= 0; row-- {
+		sum := b[row]
+		for k := row + 1; k < n; k++ {
+			sum -= a[row][k] * x[k]
+		}
+		x[row] = sum / a[row][row]
+	}
+	return x
+}
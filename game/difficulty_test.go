@@ -0,0 +1,51 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/FiveEightyEight/mwfapi/models"
+)
+
+func TestDifficultyDeterministic(t *testing.T) {
+	config := models.GameConfig{
+		Methods:        []models.GameConfigMethod{models.GameConfigMethodAdd, models.GameConfigMethodMultiply, models.GameConfigMethodFraction},
+		Range:          models.GameConfigRange{Min: 1, Max: 999},
+		MultipleChoice: true,
+	}
+	for _, problem := range GenerateGameProblems(config, 7) {
+		want := Difficulty(problem)
+		for i := 0; i < 100; i++ {
+			if got := Difficulty(problem); got != want {
+				t.Fatalf("%s rated %v, then %v", problemKey(problem), want, got)
+			}
+		}
+	}
+}
+
+func TestCalibrate(t *testing.T) {
+	easy := models.GameProblem{Number1: 2, Number2: 3, Method: models.GameConfigMethodAdd, Answer: models.IntegerValue(5)}
+	hard := models.GameProblem{Number1: 2, Number2: 3, Method: models.GameConfigMethodMultiply, Answer: models.IntegerValue(6)}
+	answers := []models.AttemptAnswer{}
+	problems := []models.GameProblem{}
+	for i := 0; i < 50; i++ {
+		problems = append(problems, easy, hard)
+		answers = append(answers, models.AttemptAnswer{Correct: true, TimeMs: 1000}, models.AttemptAnswer{Correct: false, TimeMs: 20000})
+	}
+	// Answers without a time say nothing about difficulty
+	problems = append(problems, hard)
+	answers = append(answers, models.AttemptAnswer{Correct: true})
+
+	samples := DifficultySamples(problems, answers)
+	if len(samples) != 100 {
+		t.Fatalf("expected 100 samples, got %d", len(samples))
+	}
+	model := DefaultDifficultyModel.Calibrate(samples)
+	before := DefaultDifficultyModel.Difficulty(hard) - DefaultDifficultyModel.Difficulty(easy)
+	after := model.Difficulty(hard) - model.Difficulty(easy)
+	if after <= before {
+		t.Errorf("expected calibration to widen the gap between %v and %v, got %v", easy.Method, hard.Method, after)
+	}
+	if weight := model.Weights["method:"+string(models.GameConfigMethodSquare)]; weight != DefaultDifficultyModel.Weights["method:"+string(models.GameConfigMethodSquare)] {
+		t.Errorf("expected an unsampled weight to stay put, got %v", weight)
+	}
+}
@@ -0,0 +1,155 @@
+package game
+
+import (
+	"math/rand"
+	"slices"
+
+	"github.com/FiveEightyEight/mwfapi/models"
+)
+
+// maxListedProblems caps how many problems of a method are listed. A range
+// with more has plenty to go round, and the list is only a sample of them.
+const maxListedProblems = 10000
+
+// problemListers list the problems of the methods set by one or two whole
+// numbers, every one the config can give, stopping once there are about
+// limit. Other methods draw on too many values to list.
+var problemListers = map[models.GameConfigMethod]func(config models.GameConfig, method models.GameConfigMethod, limit int) []models.GameProblem{
+	models.GameConfigMethodAdd:          listBasicProblems,
+	models.GameConfigMethodSubtract:     listBasicProblems,
+	models.GameConfigMethodMultiply:     listBasicProblems,
+	models.GameConfigMethodDivide:       listBasicProblems,
+	models.GameConfigMethodPower:        listPowerProblems,
+	models.GameConfigMethodSquare:       listPowerProblems,
+	models.GameConfigMethodCube:         listPowerProblems,
+	models.GameConfigMethodSquareRoot:   listSquareRootProblems,
+	models.GameConfigMethodPowerOfTen:   listPowerOfTenProblems,
+	models.GameConfigMethodGCD:          listGCDProblems,
+	models.GameConfigMethodLCM:          listLCMProblems,
+	models.GameConfigMethodIsPrime:      listIsPrimeProblems,
+	models.GameConfigMethodPrimeFactors: listPrimeFactorsProblems,
+	models.GameConfigMethodFactorPair:   listFactorPairProblems,
+}
+
+// listProblems lists the different problems of method the config can give,
+// or reports false if the method can't be listed.
+func listProblems(config models.GameConfig, method models.GameConfigMethod) ([]models.GameProblem, bool) {
+	lister, ok := problemListers[method]
+	if !ok {
+		return nil, false
+	}
+	problems := []models.GameProblem{}
+	seen := map[string]bool{}
+	for _, problem := range lister(methodConfig(config, method), method, maxListedProblems) {
+		if key := problemKey(problem); !seen[key] {
+			seen[key] = true
+			problems = append(problems, problem)
+		}
+	}
+	return problems, true
+}
+
+// DistinctProblemCount is how many different problems the config's methods
+// can give, at least, or false if some can't be counted. Times table drills
+// repeat facts by design and aren't counted.
+func DistinctProblemCount(config models.GameConfig) (int, bool) {
+	total := 0
+	for _, method := range config.Methods {
+		problems, ok := listProblems(config, method)
+		if !ok {
+			return 0, false
+		}
+		total += len(problems)
+	}
+	return total, true
+}
+
+// problemPool hands out problems of a config's methods without ever giving
+// the same one twice. Problems are drawn as each method's generator draws
+// them, and once that keeps turning up used ones, picked from a list of the
+// method's problems still unused. A method that can't be listed is taken to
+// have run out when its generator does.
+type problemPool struct {
+	config models.GameConfig
+	used   map[string]bool
+	unused map[models.GameConfigMethod][]models.GameProblem
+	spent  map[models.GameConfigMethod]bool
+}
+
+func newProblemPool(config models.GameConfig) *problemPool {
+	return &problemPool{
+		config: config,
+		used:   map[string]bool{},
+		unused: map[models.GameConfigMethod][]models.GameProblem{},
+		spent:  map[models.GameConfigMethod]bool{},
+	}
+}
+
+// methods returns the config's methods that still have problems to give.
+func (p *problemPool) methods() []models.GameConfigMethod {
+	methods := []models.GameConfigMethod{}
+	for _, method := range p.config.Methods {
+		if !p.spent[method] {
+			methods = append(methods, method)
+		}
+	}
+	return methods
+}
+
+// draw fills a set of up to count problems, each of a method picked at
+// random from those with problems left. It comes up short only if every
+// method runs out.
+func (p *problemPool) draw(count int, random *rand.Rand) []models.GameProblem {
+	problems := make([]models.GameProblem, 0, count)
+	for len(problems) < count {
+		methods := p.methods()
+		if len(methods) == 0 {
+			break
+		}
+		if problem, ok := p.fresh(methods[random.Intn(len(methods))], random); ok {
+			p.use(problem)
+			problems = append(problems, problem)
+		}
+	}
+	return problems
+}
+
+// fresh returns an unused problem of method without using it up, or reports
+// false, marking the method spent, if there are none left.
+func (p *problemPool) fresh(method models.GameConfigMethod, random *rand.Rand) (models.GameProblem, bool) {
+	config := methodConfig(p.config, method)
+	basic := slices.Contains(basicMethods, method)
+	for attempt := 0; attempt < duplicateAttempts; attempt++ {
+		problem := generateProblem(config, method, random)
+		// The generator falls back on a trivial basic problem when it keeps drawing them
+		trivial := basic && isTrivial(config.Exclude, method, problem.Number1, problem.Number2)
+		if !trivial && !p.used[problemKey(problem)] {
+			return problem, true
+		}
+	}
+
+	unused, listed := p.unused[method]
+	if !listed {
+		unused, _ = listProblems(p.config, method)
+	}
+	for len(unused) > 0 {
+		i := random.Intn(len(unused))
+		if problem := unused[i]; !p.used[problemKey(problem)] {
+			p.unused[method] = unused
+			if basic {
+				problem = finishBasicProblem(config, problem, random)
+			}
+			return problem, true
+		}
+		unused[i] = unused[len(unused)-1]
+		unused = unused[:len(unused)-1]
+	}
+	p.unused[method] = unused
+	p.spent[method] = true
+	return models.GameProblem{}, false
+}
+
+// use marks problem as given.
+func (p *problemPool) use(problem models.GameProblem) {
+	p.used[problemKey(problem)] = true
+}
@@ -0,0 +1,114 @@
+package game
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/FiveEightyEight/mwfapi/models"
+)
+
+func TestGenerateGameProblemsDistinct(t *testing.T) {
+	config := func(method models.GameConfigMethod, min, max int, exclude ...models.GameConfigExclusion) models.GameConfig {
+		return models.GameConfig{
+			Methods: []models.GameConfigMethod{method},
+			Range:   models.GameConfigRange{Min: min, Max: max},
+			Exclude: exclude,
+		}
+	}
+
+	tests := []struct {
+		name   string
+		config models.GameConfig
+	}{
+		{"add", config(models.GameConfigMethodAdd, 1, 12)},
+		{"small add", config(models.GameConfigMethodAdd, 1, 4)},
+		{"small subtract without zero or same", config(models.GameConfigMethodSubtract, 0, 5, models.GameConfigExcludeZero, models.GameConfigExcludeSame)},
+		{"small multiply without one", config(models.GameConfigMethodMultiply, 1, 5, models.GameConfigExcludeOne)},
+		{"small divide", config(models.GameConfigMethodDivide, 0, 4)},
+		{"missing operand", with(config(models.GameConfigMethodDivide, 1, 4), func(c *models.GameConfig) { c.MissingOperand = true })},
+		{"square", config(models.GameConfigMethodSquare, 1, 10)},
+		{"power", config(models.GameConfigMethodPower, 0, 3)},
+		{"square root", config(models.GameConfigMethodSquareRoot, 1, 10)},
+		{"gcd", config(models.GameConfigMethodGCD, 1, 12)},
+		{"lcm", config(models.GameConfigMethodLCM, 1, 6)},
+		{"is prime", config(models.GameConfigMethodIsPrime, 1, 11)},
+		{"prime factors", config(models.GameConfigMethodPrimeFactors, 2, 12)},
+		{"factor pair", config(models.GameConfigMethodFactorPair, 1, 20)},
+		{"fraction", config(models.GameConfigMethodFraction, 1, 12)},
+		{"mixed", models.GameConfig{
+			Methods: []models.GameConfigMethod{models.GameConfigMethodAdd, models.GameConfigMethodMultiply, models.GameConfigMethodGCD},
+			Range:   models.GameConfigRange{Min: 1, Max: 4},
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := ValidateGameConfig(test.config); err != nil {
+				t.Fatalf("config should be valid: %v", err)
+			}
+			for seed := int64(0); seed < 200; seed++ {
+				problems := GenerateGameProblems(test.config, seed)
+				if len(problems) != DefaultProblemCount {
+					t.Fatalf("seed %d: expected %d problems, got %d", seed, DefaultProblemCount, len(problems))
+				}
+				seen := map[string]bool{}
+				for _, problem := range problems {
+					key := problemKey(problem)
+					if seen[key] {
+						t.Fatalf("seed %d: repeated problem %s", seed, key)
+					}
+					seen[key] = true
+					if isTrivial(test.config.Exclude, problem.Method, problem.Number1, problem.Number2) {
+						t.Fatalf("seed %d: excluded problem %s", seed, key)
+					}
+				}
+				if !reflect.DeepEqual(problems, GenerateGameProblems(test.config, seed)) {
+					t.Fatalf("seed %d: problems differ between runs", seed)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateDistinctProblems(t *testing.T) {
+	config := func(method models.GameConfigMethod, min, max int, exclude ...models.GameConfigExclusion) models.GameConfig {
+		return models.GameConfig{
+			Methods: []models.GameConfigMethod{method},
+			Range:   models.GameConfigRange{Min: min, Max: max},
+			Exclude: exclude,
+		}
+	}
+
+	tests := []struct {
+		name   string
+		config models.GameConfig
+		valid  bool
+	}{
+		{"add 1 to 4", config(models.GameConfigMethodAdd, 1, 4), true},
+		{"add 1 to 3", config(models.GameConfigMethodAdd, 1, 3), false},
+		{"subtract without zero or same", config(models.GameConfigMethodSubtract, 0, 3, models.GameConfigExcludeZero, models.GameConfigExcludeSame), false},
+		{"multiply without one", config(models.GameConfigMethodMultiply, 1, 4, models.GameConfigExcludeOne), false},
+		{"divide by zero counts once", config(models.GameConfigMethodDivide, 0, 3), false},
+		{"few primes", config(models.GameConfigMethodIsPrime, 1, 9), false},
+		{"fraction", config(models.GameConfigMethodFraction, 1, 2), true},
+		{"times tables", with(config(models.GameConfigMethodMultiply, 1, 2), func(c *models.GameConfig) {
+			c.TimesTables = &models.GameConfigTimesTables{Tables: []int{2}, Repeats: 2}
+		}), true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fields := errorFields(t, ValidateGameConfig(test.config))
+			if invalid := slices.Contains(fields, "range"); invalid == test.valid {
+				t.Errorf("expected valid %v, got errors %v", test.valid, fields)
+			}
+		})
+	}
+}
+
+// with returns config after change.
+func with(config models.GameConfig, change func(*models.GameConfig)) models.GameConfig {
+	change(&config)
+	return config
+}
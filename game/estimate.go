@@ -0,0 +1,86 @@
+package game
+
+import (
+	"math/big"
+	"math/rand"
+
+	"github.com/FiveEightyEight/mwfapi/models"
+)
+
+const (
+	DefaultEstimationDisplayMs = 5000
+	MaxEstimationDisplayMs     = 60000
+	MaxBandPoints              = 5
+	MaxErrorBands              = 5
+)
+
+var defaultErrorBands = []models.GameConfigErrorBand{
+	{Within: 1, Points: 3},
+	{Within: 5, Points: 2},
+	{Within: 10, Points: 1},
+}
+
+func estimationSettings(config models.GameConfig) models.GameConfigEstimation {
+	var settings models.GameConfigEstimation
+	if config.Estimation != nil {
+		settings = *config.Estimation
+	}
+	if len(settings.Operations) == 0 {
+		settings.Operations = basicMethods
+	}
+	if len(settings.Bands) == 0 {
+		settings.Bands = defaultErrorBands
+	}
+	if settings.DisplayMs == 0 {
+		settings.DisplayMs = DefaultEstimationDisplayMs
+	}
+	return settings
+}
+
+// generateEstimateProblem builds an operation over operands from the config's
+// range to be estimated rather than worked out. Division need not come out
+// exact, so the true answer may be a fraction.
+func generateEstimateProblem(config models.GameConfig, random *rand.Rand) models.GameProblem {
+	settings := estimationSettings(config)
+	operation := settings.Operations[random.Intn(len(settings.Operations))]
+
+	num1, num2 := randomIn(config.Range, random), randomIn(config.Range, random)
+	if num2 > num1 {
+		num1, num2 = num2, num1
+	}
+	if operation == models.GameConfigMethodDivide && num2 == 0 {
+		num2 = 1
+	}
+
+	answer, _ := applyRat(operation, big.NewRat(int64(num1), 1), big.NewRat(int64(num2), 1))
+	return models.GameProblem{
+		Number1:   num1,
+		Number2:   num2,
+		Method:    models.GameConfigMethodEstimate,
+		Operation: operation,
+		Answer:    valueOfRat(answer),
+		Text:      renderOperation(operation, models.IntegerValue(num1), models.IntegerValue(num2)),
+		DisplayMs: settings.DisplayMs,
+	}
+}
+
+// scoreEstimate awards the points of the best band submitted falls in. When
+// the true answer is zero only an exact estimate counts.
+func scoreEstimate(config models.GameConfig, submitted, expected models.Value) int {
+	if !submitted.IsNumber() {
+		return 0
+	}
+	diff := new(big.Rat).Sub(ratOf(submitted), ratOf(expected))
+	diff.Abs(diff)
+
+	points := 0
+	for _, band := range estimationSettings(config).Bands {
+		// diff <= |expected| * within / 100
+		allowed := new(big.Rat).Abs(ratOf(expected))
+		allowed.Mul(allowed, big.NewRat(int64(band.Within), 100))
+		if diff.Cmp(allowed) <= 0 && band.Points > points {
+			points = band.Points
+		}
+	}
+	return points
+}
@@ -0,0 +1,67 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/FiveEightyEight/mwfapi/models"
+)
+
+func TestScoreEstimate(t *testing.T) {
+	problem := models.GameProblem{
+		Number1:   48,
+		Number2:   52,
+		Method:    models.GameConfigMethodEstimate,
+		Operation: models.GameConfigMethodMultiply,
+		Answer:    models.IntegerValue(2496),
+	}
+	config := models.GameConfig{Methods: []models.GameConfigMethod{models.GameConfigMethodEstimate}}
+
+	tests := []struct {
+		name     string
+		estimate models.Value
+		points   int
+	}{
+		{"exact", models.IntegerValue(2496), 3},
+		{"within 1%", models.IntegerValue(2500), 3},
+		{"within 5%", models.IntegerValue(2400), 2},
+		{"within 10%", models.IntegerValue(2700), 1},
+		{"too far", models.IntegerValue(3000), 0},
+		{"not a number", models.BooleanValue(true), 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if points := ScoreAnswer(config, problem, test.estimate); points != test.points {
+				t.Errorf("expected %d points, got %d", test.points, points)
+			}
+		})
+	}
+
+	config.Estimation = &models.GameConfigEstimation{Bands: []models.GameConfigErrorBand{{Within: 20, Points: 4}}}
+	if points := ScoreAnswer(config, problem, models.IntegerValue(2900)); points != 4 {
+		t.Errorf("expected the config's own band to award 4 points, got %d", points)
+	}
+
+	zero := models.GameProblem{Method: models.GameConfigMethodEstimate, Operation: models.GameConfigMethodSubtract, Number1: 7, Number2: 7, Answer: models.IntegerValue(0)}
+	if points := ScoreAnswer(config, zero, models.IntegerValue(1)); points != 0 {
+		t.Errorf("expected only an exact estimate of zero to count, got %d points", points)
+	}
+}
+
+func TestGenerateEstimateProblem(t *testing.T) {
+	config := models.GameConfig{
+		Methods:    []models.GameConfigMethod{models.GameConfigMethodEstimate},
+		Range:      models.GameConfigRange{Min: 0, Max: 999},
+		Estimation: &models.GameConfigEstimation{DisplayMs: 3000},
+	}
+	for seed := int64(0); seed < 20; seed++ {
+		for _, problem := range GenerateGameProblems(config, seed) {
+			want, ok := applyRat(problem.Operation, ratOf(models.IntegerValue(problem.Number1)), ratOf(models.IntegerValue(problem.Number2)))
+			if !ok || want.Cmp(ratOf(problem.Answer)) != 0 {
+				t.Fatalf("seed %d: %s should be %s, got %s", seed, problem.Text, want, problem.Answer)
+			}
+			if problem.DisplayMs != 3000 {
+				t.Fatalf("seed %d: expected %s shown for 3000ms, got %d", seed, problem.Text, problem.DisplayMs)
+			}
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package game
+
+import (
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/FiveEightyEight/mwfapi/models"
+)
+
+const (
+	DefaultExpressionOperators = 3
+	DefaultExpressionMaxResult = 100
+	MaxExpressionOperators     = 6
+	MaxExpressionDepth         = 3
+
+	expressionAttempts = 200
+	leafPrecedence     = 3
+)
+
+var basicMethods = []models.GameConfigMethod{
+	models.GameConfigMethodAdd,
+	models.GameConfigMethodSubtract,
+	models.GameConfigMethodMultiply,
+	models.GameConfigMethodDivide,
+}
+
+var operatorSymbols = map[models.GameConfigMethod]string{
+	models.GameConfigMethodAdd:      "+",
+	models.GameConfigMethodSubtract: "-",
+	models.GameConfigMethodMultiply: "×",
+	models.GameConfigMethodDivide:   "÷",
+}
+
+func precedence(operator models.GameConfigMethod) int {
+	switch operator {
+	case models.GameConfigMethodAdd, models.GameConfigMethodSubtract:
+		return 1
+	case models.GameConfigMethodMultiply, models.GameConfigMethodDivide:
+		return 2
+	}
+	return leafPrecedence
+}
+
+// EvaluateExpression computes the value of expr. It reports false if the
+// expression divides by zero or a division does not come out exact.
+func EvaluateExpression(expr *models.Expression) (int, bool) {
+	if expr == nil {
+		return 0, false
+	}
+	if expr.Value != nil {
+		return *expr.Value, true
+	}
+	left, ok := EvaluateExpression(expr.Left)
+	if !ok {
+		return 0, false
+	}
+	right, ok := EvaluateExpression(expr.Right)
+	if !ok {
+		return 0, false
+	}
+	if expr.Operator == models.GameConfigMethodDivide && (right == 0 || left%right != 0) {
+		return 0, false
+	}
+	return applyMethod(expr.Operator, left, right)
+}
+
+// RenderExpression writes expr in its canonical text form, using only the
+// parentheses that order of operations requires.
+func RenderExpression(expr *models.Expression) string {
+	var sb strings.Builder
+	renderExpression(&sb, expr)
+	return sb.String()
+}
+
+func renderExpression(sb *strings.Builder, expr *models.Expression) {
+	if expr.Value != nil {
+		sb.WriteString(strconv.Itoa(*expr.Value))
+		return
+	}
+	renderOperand(sb, expr.Left, precedence(expr.Operator))
+	sb.WriteString(" " + operatorSymbols[expr.Operator] + " ")
+	renderOperand(sb, expr.Right, precedence(expr.Operator)+1)
+}
+
+// renderOperand wraps operand in parentheses when its precedence is below
+// minPrecedence. Right operands need one more than their parent so that
+// a - (b - c) keeps its parentheses.
+func renderOperand(sb *strings.Builder, operand *models.Expression, minPrecedence int) {
+	if precedence(operand.Operator) >= minPrecedence {
+		renderExpression(sb, operand)
+		return
+	}
+	sb.WriteString("(")
+	renderExpression(sb, operand)
+	sb.WriteString(")")
+}
+
+// expressionSettings fills in defaults for an expression config.
+func expressionSettings(config models.GameConfig) models.GameConfigExpression {
+	settings := models.GameConfigExpression{Operators: DefaultExpressionOperators}
+	if config.Expression != nil {
+		settings = *config.Expression
+	}
+	if len(settings.Operations) == 0 {
+		settings.Operations = basicMethods
+	}
+	if settings.Operators == 0 {
+		settings.Operators = DefaultExpressionOperators
+	}
+	if settings.MaxResult == 0 {
+		settings.MaxResult = DefaultExpressionMaxResult
+	}
+	return settings
+}
+
+type expressionGenerator struct {
+	settings models.GameConfigExpression
+	operands models.GameConfigRange
+	random   *rand.Rand
+}
+
+// generateExpressionProblem builds a random expression whose every step is a
+// whole number between 0 and the configured max result.
+func generateExpressionProblem(config models.GameConfig, random *rand.Rand) models.GameProblem {
+	g := expressionGenerator{
+		settings: expressionSettings(config),
+		operands: config.Range,
+		random:   random,
+	}
+
+	var expr *models.Expression
+	var value int
+	ok := false
+	for attempt := 0; attempt < expressionAttempts && !ok; attempt++ {
+		expr, value, ok = g.node(g.settings.Operators, g.settings.Depth, 0)
+	}
+	if !ok {
+		// Every configured operation keeps failing, fall back to a sum
+		left, right := g.leaf(), g.leaf()
+		expr = &models.Expression{Operator: models.GameConfigMethodAdd, Left: left, Right: right}
+		value, _ = EvaluateExpression(expr)
+	}
+
+	return models.GameProblem{
+		Method:     models.GameConfigMethodExpression,
+		Answer:     models.IntegerValue(value),
+		Expression: expr,
+		Text:       RenderExpression(expr),
+	}
+}
+
+func (g *expressionGenerator) leaf() *models.Expression {
+	value := g.random.Intn(g.operands.Max-g.operands.Min+1) + g.operands.Min
+	return &models.Expression{Value: &value}
+}
+
+// node generates a subtree with the given number of operators. A subtree
+// whose operator binds looser than minPrecedence is rendered in parentheses,
+// which is only allowed while depth remains.
+func (g *expressionGenerator) node(operators, depth, minPrecedence int) (*models.Expression, int, bool) {
+	if operators == 0 {
+		leaf := g.leaf()
+		return leaf, *leaf.Value, true
+	}
+
+	var candidates []models.GameConfigMethod
+	for _, operation := range g.settings.Operations {
+		if depth > 0 || precedence(operation) >= minPrecedence {
+			candidates = append(candidates, operation)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, 0, false
+	}
+	operator := candidates[g.random.Intn(len(candidates))]
+	innerDepth := depth
+	if precedence(operator) < minPrecedence {
+		innerDepth--
+	}
+
+	// Without parentheses left, a right operand can only hold operators that
+	// bind tighter than this one
+	leftOperators := g.random.Intn(operators)
+	if innerDepth == 0 && !g.hasOperationAbove(precedence(operator)) {
+		leftOperators = operators - 1
+	}
+	rightOperators := operators - 1 - leftOperators
+
+	right, rightValue, ok := g.node(rightOperators, innerDepth, precedence(operator)+1)
+	if !ok {
+		return nil, 0, false
+	}
+
+	var left *models.Expression
+	var leftValue int
+	if operator == models.GameConfigMethodDivide && leftOperators == 0 && rightValue != 0 {
+		// Build the dividend from the divisor so the division comes out exact
+		quotient := g.leaf()
+		leftValue = rightValue * *quotient.Value
+		left = &models.Expression{Value: &leftValue}
+	} else {
+		left, leftValue, ok = g.node(leftOperators, innerDepth, precedence(operator))
+		if !ok {
+			return nil, 0, false
+		}
+	}
+
+	expr := &models.Expression{Operator: operator, Left: left, Right: right}
+	if operator == models.GameConfigMethodDivide && (rightValue == 0 || leftValue%rightValue != 0) {
+		return nil, 0, false
+	}
+	value, _ := applyMethod(operator, leftValue, rightValue)
+	if value < 0 || value > g.settings.MaxResult || leftValue > g.settings.MaxResult {
+		return nil, 0, false
+	}
+	return expr, value, true
+}
+
+func (g *expressionGenerator) hasOperationAbove(minPrecedence int) bool {
+	for _, operation := range g.settings.Operations {
+		if precedence(operation) > minPrecedence {
+			return true
+		}
+	}
+	return false
+}
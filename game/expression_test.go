@@ -0,0 +1,93 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/FiveEightyEight/mwfapi/models"
+)
+
+func TestRenderExpression(t *testing.T) {
+	leaf := func(value int) *models.Expression { return &models.Expression{Value: &value} }
+	op := func(operator models.GameConfigMethod, left, right *models.Expression) *models.Expression {
+		return &models.Expression{Operator: operator, Left: left, Right: right}
+	}
+
+	tests := []struct {
+		name  string
+		expr  *models.Expression
+		text  string
+		value int
+	}{
+		{"multiplication first", op(models.GameConfigMethodAdd, leaf(2), op(models.GameConfigMethodMultiply, leaf(3), leaf(4))), "2 + 3 × 4", 14},
+		{"parenthesised sum", op(models.GameConfigMethodMultiply, op(models.GameConfigMethodAdd, leaf(2), leaf(3)), leaf(4)), "(2 + 3) × 4", 20},
+		{"left to right", op(models.GameConfigMethodSubtract, op(models.GameConfigMethodSubtract, leaf(9), leaf(3)), leaf(2)), "9 - 3 - 2", 4},
+		{"right grouping", op(models.GameConfigMethodSubtract, leaf(9), op(models.GameConfigMethodSubtract, leaf(3), leaf(2))), "9 - (3 - 2)", 8},
+		{"exact division", op(models.GameConfigMethodDivide, leaf(12), op(models.GameConfigMethodDivide, leaf(6), leaf(3))), "12 ÷ (6 ÷ 3)", 6},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if text := RenderExpression(test.expr); text != test.text {
+				t.Errorf("expected %q, got %q", test.text, text)
+			}
+			if value, ok := EvaluateExpression(test.expr); !ok || value != test.value {
+				t.Errorf("expected %d, got %d, %v", test.value, value, ok)
+			}
+		})
+	}
+
+	if _, ok := EvaluateExpression(op(models.GameConfigMethodDivide, leaf(7), leaf(2))); ok {
+		t.Error("expected an inexact division not to evaluate")
+	}
+	if _, ok := EvaluateExpression(op(models.GameConfigMethodDivide, leaf(7), leaf(0))); ok {
+		t.Error("expected division by zero not to evaluate")
+	}
+}
+
+func TestGenerateExpressionProblem(t *testing.T) {
+	config := models.GameConfig{
+		Methods:    []models.GameConfigMethod{models.GameConfigMethodExpression},
+		Range:      models.GameConfigRange{Min: 1, Max: 12},
+		Expression: &models.GameConfigExpression{Operators: 3, Depth: 1, MaxResult: 50},
+	}
+	var operators func(expr *models.Expression) int
+	operators = func(expr *models.Expression) int {
+		if expr.Value != nil {
+			return 0
+		}
+		return 1 + operators(expr.Left) + operators(expr.Right)
+	}
+
+	for seed := int64(0); seed < 50; seed++ {
+		for _, problem := range GenerateGameProblems(config, seed) {
+			value, ok := EvaluateExpression(problem.Expression)
+			if !ok || !CheckAnswer(config, problem, models.IntegerValue(value)) {
+				t.Fatalf("seed %d: %s evaluates to %d, answer %v", seed, problem.Text, value, problem.Answer)
+			}
+			if value < 0 || value > 50 {
+				t.Fatalf("seed %d: %s = %d is outside 0 to 50", seed, problem.Text, value)
+			}
+			if n := operators(problem.Expression); n != 3 {
+				t.Fatalf("seed %d: %s has %d operators", seed, problem.Text, n)
+			}
+			if nesting(problem.Text) > 1 {
+				t.Fatalf("seed %d: %s nests more parentheses than its depth allows", seed, problem.Text)
+			}
+		}
+	}
+}
+
+// nesting returns how deeply the parentheses in text nest.
+func nesting(text string) int {
+	depth, deepest := 0, 0
+	for _, r := range text {
+		switch r {
+		case '(':
+			depth++
+			deepest = max(deepest, depth)
+		case ')':
+			depth--
+		}
+	}
+	return deepest
+}
@@ -0,0 +1,131 @@
+package game
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/FiveEightyEight/mwfapi/models"
+)
+
+// fairCandidates is how many problems are drawn for each slot of a fair
+// set, the closest in difficulty being kept.
+const fairCandidates = 8
+
+// GenerateFairProblemSets builds a problem set for each of players, with no
+// problem appearing in more than one. The first set is the one
+// GenerateGameProblems would build, and every other set follows it method
+// for method, picking for each slot the candidate that keeps its running
+// total difficulty closest to the first set's, so all sets end up as long
+// and as difficult. Where a method runs out, the slot goes to another, and
+// should every method run out the later sets come up short; MaxFairPlayers
+// says how many players that leaves room for. Adding players leaves the
+// earlier sets as they were.
+func GenerateFairProblemSets(config models.GameConfig, seed int64, players int) [][]models.GameProblem {
+	random := rand.New(rand.NewSource(seed))
+	count := config.ProblemCount
+	if count == 0 {
+		count = DefaultProblemCount
+	}
+	pool := newProblemPool(config)
+	reference := pool.draw(count, random)
+
+	sets := [][]models.GameProblem{reference}
+	for player := 1; player < players; player++ {
+		sets = append(sets, fairProblemSet(config, seed, player, pool, reference))
+	}
+
+	// Choices go in last, as they change how difficult a problem looks
+	if config.MultipleChoice {
+		for i := range reference {
+			addChoices(config, &reference[i], random)
+		}
+	}
+	return sets
+}
+
+// NextFairProblemSet builds the set for a player joining a game whose other
+// players already hold sets, matched to the first of them like every set
+// GenerateFairProblemSets builds, and sharing no problem with any.
+func NextFairProblemSet(config models.GameConfig, seed int64, sets [][]models.GameProblem) []models.GameProblem {
+	if len(sets) == 0 {
+		return GenerateFairProblemSets(config, seed, 1)[0]
+	}
+	pool := newProblemPool(config)
+	for _, set := range sets {
+		for _, problem := range set {
+			problem.Choices = nil
+			pool.use(problem)
+		}
+	}
+	return fairProblemSet(config, seed, len(sets), pool, sets[0])
+}
+
+// MaxFairPlayers is how many players can each have a set of their own
+// without repeating a problem, or false if there is no telling.
+func MaxFairPlayers(config models.GameConfig) (int, bool) {
+	possible, ok := DistinctProblemCount(config)
+	if !ok {
+		return 0, false
+	}
+	count := config.ProblemCount
+	if count == 0 {
+		count = DefaultProblemCount
+	}
+	return possible / count, true
+}
+
+// fairProblemSet builds the set of the given player, drawing from pool
+// problems to match reference.
+func fairProblemSet(config models.GameConfig, seed int64, player int, pool *problemPool, reference []models.GameProblem) []models.GameProblem {
+	random := rand.New(rand.NewSource(seed + int64(player)))
+	set := make([]models.GameProblem, 0, len(reference))
+	drift := 0.0
+	for _, target := range reference {
+		target.Choices = nil
+		problem, ok := pool.closest(target.Method, Difficulty(target)-drift, random)
+		if !ok {
+			break
+		}
+		pool.use(problem)
+		set = append(set, problem)
+		drift += Difficulty(problem) - Difficulty(target)
+	}
+	if config.MultipleChoice {
+		for i := range set {
+			addChoices(config, &set[i], random)
+		}
+	}
+	return set
+}
+
+// closest returns the unused problem of method, out of a few candidates,
+// rated closest to want. Once method runs out, candidates are of any method
+// with problems left. It reports false if there are none at all.
+func (p *problemPool) closest(method models.GameConfigMethod, want float64, random *rand.Rand) (models.GameProblem, bool) {
+	var best models.GameProblem
+	bestGap := math.Inf(1)
+	for attempt := 0; attempt < fairCandidates || math.IsInf(bestGap, 1); attempt++ {
+		methods := []models.GameConfigMethod{method}
+		if p.spent[method] {
+			if methods = p.methods(); len(methods) == 0 {
+				break
+			}
+		}
+		candidate, ok := p.fresh(methods[random.Intn(len(methods))], random)
+		if !ok {
+			continue
+		}
+		if gap := math.Abs(Difficulty(candidate) - want); gap < bestGap {
+			best, bestGap = candidate, gap
+		}
+	}
+	return best, !math.IsInf(bestGap, 1)
+}
+
+func totalDifficulty(problems []models.GameProblem) float64 {
+	total := 0.0
+	for _, problem := range problems {
+		total += Difficulty(problem)
+	}
+	return total
+}
@@ -0,0 +1,114 @@
+package game
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/FiveEightyEight/mwfapi/models"
+)
+
+func TestGenerateFairProblemSets(t *testing.T) {
+	tests := []struct {
+		name   string
+		config models.GameConfig
+	}{
+		{"wide range", models.GameConfig{
+			Methods: []models.GameConfigMethod{models.GameConfigMethodAdd, models.GameConfigMethodMultiply},
+			Range:   models.GameConfigRange{Min: 1, Max: 100},
+		}},
+		{"small range", models.GameConfig{
+			Methods: []models.GameConfigMethod{models.GameConfigMethodAdd, models.GameConfigMethodSubtract},
+			Range:   models.GameConfigRange{Min: 1, Max: 6},
+		}},
+		{"multiple choice", models.GameConfig{
+			Methods:        []models.GameConfigMethod{models.GameConfigMethodMultiply, models.GameConfigMethodGCD},
+			Range:          models.GameConfigRange{Min: 1, Max: 12},
+			MultipleChoice: true,
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			players, ok := MaxFairPlayers(test.config)
+			if !ok {
+				t.Fatal("expected the problems to be countable")
+			}
+			players = min(players, 6)
+			for seed := int64(0); seed < 20; seed++ {
+				sets := GenerateFairProblemSets(test.config, seed, players)
+				if !reflect.DeepEqual(sets, GenerateFairProblemSets(test.config, seed, players)) {
+					t.Fatalf("seed %d: sets differ between runs", seed)
+				}
+				if !reflect.DeepEqual(sets[0], GenerateGameProblems(test.config, seed)) {
+					t.Fatalf("seed %d: first set differs from the game's problems", seed)
+				}
+				if fewer := GenerateFairProblemSets(test.config, seed, players-1); !reflect.DeepEqual(fewer, sets[:players-1]) {
+					t.Fatalf("seed %d: adding a player changed the earlier sets", seed)
+				}
+
+				seen := map[string]bool{}
+				for player, set := range sets {
+					if len(set) != DefaultProblemCount {
+						t.Fatalf("seed %d: player %d got %d problems", seed, player, len(set))
+					}
+					for _, problem := range set {
+						problem.Choices = nil
+						if key := problemKey(problem); seen[key] {
+							t.Fatalf("seed %d: player %d got a problem already dealt, %s", seed, player, key)
+						} else {
+							seen[key] = true
+						}
+					}
+				}
+
+				next := NextFairProblemSet(test.config, seed, sets[:players-1])
+				if !reflect.DeepEqual(next, NextFairProblemSet(test.config, seed, sets[:players-1])) {
+					t.Fatalf("seed %d: late joiner's set differs between runs", seed)
+				}
+				for _, problem := range next {
+					problem.Choices = nil
+					for _, set := range sets[:players-1] {
+						for _, dealt := range set {
+							dealt.Choices = nil
+							if problemKey(problem) == problemKey(dealt) {
+								t.Fatalf("seed %d: late joiner got a problem already dealt, %s", seed, problemKey(problem))
+							}
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestFairProblemSetsDifficulty(t *testing.T) {
+	config := models.GameConfig{
+		Methods: []models.GameConfigMethod{models.GameConfigMethodAdd, models.GameConfigMethodMultiply},
+		Range:   models.GameConfigRange{Min: 1, Max: 100},
+	}
+	for seed := int64(0); seed < 20; seed++ {
+		sets := GenerateFairProblemSets(config, seed, 4)
+		want := totalDifficulty(sets[0])
+		for player, set := range sets[1:] {
+			if got := totalDifficulty(set); math.Abs(got-want) > 1 {
+				t.Errorf("seed %d: player %d's set rates %.2f against %.2f", seed, player+1, got, want)
+			}
+		}
+	}
+}
+
+func TestMaxFairPlayers(t *testing.T) {
+	config := models.GameConfig{
+		Methods: []models.GameConfigMethod{models.GameConfigMethodAdd},
+		Range:   models.GameConfigRange{Min: 1, Max: 6},
+	}
+	// 21 sums of two numbers from 1 to 6, ignoring order
+	if players, ok := MaxFairPlayers(config); !ok || players != 2 {
+		t.Errorf("expected room for 2 players, got %d, %v", players, ok)
+	}
+	config.Methods = []models.GameConfigMethod{models.GameConfigMethodFraction}
+	if _, ok := MaxFairPlayers(config); ok {
+		t.Error("expected fraction problems not to be counted")
+	}
+}
@@ -0,0 +1,124 @@
+package game
+
+import (
+	"math/big"
+	"math/rand"
+	"strings"
+
+	"github.com/FiveEightyEight/mwfapi/models"
+)
+
+const (
+	DefaultMaxDenominator  = 12
+	MaxFractionDenominator = 100
+	maxMixedWhole          = 3
+)
+
+func ratOf(v models.Value) *big.Rat {
+	return big.NewRat(v.Numerator, v.Denominator)
+}
+
+// valueOfRat turns r into a value in simplest form, an integer if it is whole.
+func valueOfRat(r *big.Rat) models.Value {
+	if r.IsInt() {
+		return models.IntegerValue(int(r.Num().Int64()))
+	}
+	return models.FractionValue(r.Num().Int64(), r.Denom().Int64())
+}
+
+// applyRat applies operation to a and b exactly. It reports false when
+// dividing by zero.
+func applyRat(operation models.GameConfigMethod, a, b *big.Rat) (*big.Rat, bool) {
+	result := new(big.Rat)
+	switch operation {
+	case models.GameConfigMethodAdd:
+		return result.Add(a, b), true
+	case models.GameConfigMethodSubtract:
+		return result.Sub(a, b), true
+	case models.GameConfigMethodMultiply:
+		return result.Mul(a, b), true
+	case models.GameConfigMethodDivide:
+		if b.Sign() == 0 {
+			return nil, false
+		}
+		return result.Quo(a, b), true
+	}
+	return nil, false
+}
+
+// isSimplest reports whether v is written in lowest terms. Whole numbers
+// must be written without a denominator.
+func isSimplest(v models.Value) bool {
+	if v.Kind != models.ValueKindFraction {
+		return true
+	}
+	if v.Denominator == 1 {
+		return false
+	}
+	return new(big.Int).GCD(nil, nil, big.NewInt(abs64(v.Numerator)), big.NewInt(v.Denominator)).Int64() == 1
+}
+
+func fractionSettings(config models.GameConfig) models.GameConfigFractions {
+	var settings models.GameConfigFractions
+	if config.Fractions != nil {
+		settings = *config.Fractions
+	}
+	if len(settings.Operations) == 0 {
+		settings.Operations = basicMethods
+	}
+	if settings.MaxDenominator == 0 {
+		settings.MaxDenominator = DefaultMaxDenominator
+	}
+	return settings
+}
+
+// generateFractionProblem builds a problem over two proper fractions or, when
+// enabled, mixed numbers. Subtraction never goes below zero.
+func generateFractionProblem(config models.GameConfig, random *rand.Rand) models.GameProblem {
+	settings := fractionSettings(config)
+	operation := settings.Operations[random.Intn(len(settings.Operations))]
+
+	operand1 := randomFraction(settings, random)
+	operand2 := randomFraction(settings, random)
+	if operation == models.GameConfigMethodSubtract && ratOf(operand1).Cmp(ratOf(operand2)) < 0 {
+		operand1, operand2 = operand2, operand1
+	}
+
+	result, _ := applyRat(operation, ratOf(operand1), ratOf(operand2))
+	return models.GameProblem{
+		Method:    models.GameConfigMethodFraction,
+		Operation: operation,
+		Operands:  []models.Value{operand1, operand2},
+		Answer:    valueOfRat(result),
+		Text:      renderOperation(operation, operand1, operand2),
+	}
+}
+
+func randomFraction(settings models.GameConfigFractions, random *rand.Rand) models.Value {
+	denominator := int64(random.Intn(settings.MaxDenominator-1) + 2)
+	numerator := int64(random.Intn(int(denominator)-1) + 1)
+
+	// Operands are always shown in lowest terms
+	divisor := new(big.Int).GCD(nil, nil, big.NewInt(numerator), big.NewInt(denominator)).Int64()
+	numerator, denominator = numerator/divisor, denominator/divisor
+	if settings.MixedNumbers && random.Intn(2) == 0 {
+		whole := int64(random.Intn(maxMixedWhole) + 1)
+		return models.MixedValue(whole*denominator+numerator, denominator)
+	}
+	return models.FractionValue(numerator, denominator)
+}
+
+func renderOperation(operation models.GameConfigMethod, operands ...models.Value) string {
+	texts := make([]string, len(operands))
+	for i, operand := range operands {
+		texts[i] = operand.String()
+	}
+	return strings.Join(texts, " "+operatorSymbols[operation]+" ")
+}
+
+func abs64(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
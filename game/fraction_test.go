@@ -0,0 +1,58 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/FiveEightyEight/mwfapi/models"
+)
+
+func TestGenerateFractionProblem(t *testing.T) {
+	config := models.GameConfig{
+		Methods:   []models.GameConfigMethod{models.GameConfigMethodFraction},
+		Range:     models.GameConfigRange{Min: 1, Max: 12},
+		Fractions: &models.GameConfigFractions{MaxDenominator: 8, MixedNumbers: true, RequireSimplestForm: true},
+	}
+	mixed := false
+	for seed := int64(0); seed < 50; seed++ {
+		for _, problem := range GenerateGameProblems(config, seed) {
+			for _, operand := range problem.Operands {
+				if !isSimplest(operand) || operand.Denominator > 8 {
+					t.Fatalf("seed %d: operand %s of %s is not in lowest terms over at most 8", seed, operand, problem.Text)
+				}
+				mixed = mixed || operand.Mixed
+			}
+			result, ok := applyRat(problem.Operation, ratOf(problem.Operands[0]), ratOf(problem.Operands[1]))
+			if !ok || result.Cmp(ratOf(problem.Answer)) != 0 {
+				t.Fatalf("seed %d: %s should be %s, got %s", seed, problem.Text, result, problem.Answer)
+			}
+			if result.Sign() < 0 {
+				t.Fatalf("seed %d: %s goes below zero", seed, problem.Text)
+			}
+			if !CheckAnswer(config, problem, problem.Answer) {
+				t.Fatalf("seed %d: %s rejects its own answer %s under simplest form", seed, problem.Text, problem.Answer)
+			}
+		}
+	}
+	if !mixed {
+		t.Error("expected some operands to be mixed numbers")
+	}
+}
+
+func TestIsSimplest(t *testing.T) {
+	tests := []struct {
+		value models.Value
+		want  bool
+	}{
+		{models.FractionValue(1, 2), true},
+		{models.FractionValue(2, 4), false},
+		{models.FractionValue(-3, 4), true},
+		{models.FractionValue(4, 1), false},
+		{models.MixedValue(7, 2), true},
+		{models.IntegerValue(4), true},
+	}
+	for _, test := range tests {
+		if got := isSimplest(test.value); got != test.want {
+			t.Errorf("%s: expected %v, got %v", test.value, test.want, got)
+		}
+	}
+}
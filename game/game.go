@@ -1,49 +1,265 @@
 package game
 
 import (
+	"encoding/json"
 	"math/rand"
-	"time"
 
 	"github.com/FiveEightyEight/mwfapi/models"
 )
 
-func GenerateGameProblems(config models.GameConfig) []models.GameProblem {
-	random := rand.New(rand.NewSource(time.Now().UnixNano()))
-	problems := make([]models.GameProblem, 10)
-
-	for i := 0; i < 10; i++ {
-		method := config.Methods[rand.Intn(len(config.Methods))]
-		num1 := random.Intn(config.Range.Max-config.Range.Min+1) + config.Range.Min
-		num2 := random.Intn(config.Range.Max-config.Range.Min+1) + config.Range.Min
-
-		if num2 > num1 {
-			num1, num2 = num2, num1
-		}
-
-		var answer int
-		switch method {
-		case models.GameConfigMethodAdd:
-			answer = num1 + num2
-		case models.GameConfigMethodSubtract:
-			answer = num1 - num2
-		case models.GameConfigMethodMultiply:
-			answer = num1 * num2
-		case models.GameConfigMethodDivide:
-			if num2 != 0 {
-				answer = num1 / num2
-			} else {
-				num2 = 1
-				answer = num1
-			}
+// MaxSeed keeps seeds within the integers a JavaScript client can hold exactly,
+// so a seed can be shared and sent back without losing precision.
+const MaxSeed = 1<<53 - 1
+
+const (
+	duplicateAttempts = 50
+	trivialAttempts   = 50
+)
+
+// NewSeed returns a random seed for GenerateGameProblems.
+func NewSeed() int64 {
+	return rand.Int63n(MaxSeed + 1)
+}
+
+// GenerateGameProblems builds the problem set for config. All randomness comes
+// from seed, so the same seed and config always yield the same problems. No
+// problem is repeated unless a times table drill asks for each fact more than
+// once. A config with too few problems to fill the set, which validation
+// rejects where it can count them, gets a shorter set.
+func GenerateGameProblems(config models.GameConfig, seed int64) []models.GameProblem {
+	random := rand.New(rand.NewSource(seed))
+	var problems []models.GameProblem
+	if config.TimesTables != nil {
+		problems = generateTimesTableProblems(config, random)
+	} else {
+		problems = generateDistinctProblems(config, random)
+	}
+
+	if config.MultipleChoice {
+		for i := range problems {
+			addChoices(config, &problems[i], random)
 		}
+	}
+
+	return problems
+}
 
-		problems[i] = models.GameProblem{
-			Number1: num1,
-			Number2: num2,
-			Method:  method,
-			Answer:  answer,
+// generateDistinctProblems draws the problem set one problem at a time, each
+// of a method picked at random, never repeating one.
+func generateDistinctProblems(config models.GameConfig, random *rand.Rand) []models.GameProblem {
+	count := config.ProblemCount
+	if count == 0 {
+		count = DefaultProblemCount
+	}
+	return newProblemPool(config).draw(count, random)
+}
+
+// methodConfig is config as it applies to method, with the method's own
+// range in place of the shared one.
+func methodConfig(config models.GameConfig, method models.GameConfigMethod) models.GameConfig {
+	if r, ok := config.MethodRanges[method]; ok {
+		config.Range = r
+	}
+	return config
+}
+
+// problemKey identifies a problem by what is asked, ignoring wording that
+// only varies its presentation, such as the names in a word problem.
+func problemKey(problem models.GameProblem) string {
+	problem.Text = ""
+	key, _ := json.Marshal(problem)
+	return string(key)
+}
+
+func generateProblem(config models.GameConfig, method models.GameConfigMethod, random *rand.Rand) models.GameProblem {
+	switch method {
+	case models.GameConfigMethodExpression:
+		return generateExpressionProblem(config, random)
+	case models.GameConfigMethodFraction:
+		return generateFractionProblem(config, random)
+	case models.GameConfigMethodDecimal:
+		return generateDecimalProblem(config, random)
+	case models.GameConfigMethodPercentOf:
+		return generatePercentOfProblem(config, random)
+	case models.GameConfigMethodPercentRatio:
+		return generatePercentRatioProblem(config, random)
+	case models.GameConfigMethodPower, models.GameConfigMethodSquare, models.GameConfigMethodCube:
+		return generatePowerProblem(method, config, random)
+	case models.GameConfigMethodSquareRoot:
+		return generateSquareRootProblem(config, random)
+	case models.GameConfigMethodPowerOfTen:
+		return generatePowerOfTenProblem(config, random)
+	case models.GameConfigMethodGCD:
+		return generateGCDProblem(config, random)
+	case models.GameConfigMethodLCM:
+		return generateLCMProblem(config, random)
+	case models.GameConfigMethodIsPrime:
+		return generateIsPrimeProblem(config, random)
+	case models.GameConfigMethodPrimeFactors:
+		return generatePrimeFactorsProblem(config, random)
+	case models.GameConfigMethodFactorPair:
+		return generateFactorPairProblem(config, random)
+	case models.GameConfigMethodSequence:
+		return generateSequenceProblem(config, random)
+	case models.GameConfigMethodCompare:
+		return generateCompareProblem(config, random)
+	case models.GameConfigMethodEstimate:
+		return generateEstimateProblem(config, random)
+	case models.GameConfigMethodTarget:
+		return generateTargetProblem(config, random)
+	}
+
+	var problem models.GameProblem
+	for attempt := 0; attempt < trivialAttempts; attempt++ {
+		problem = basicProblem(config, method, randomIn(config.Range, random), randomIn(config.Range, random))
+		if !isTrivial(config.Exclude, method, problem.Number1, problem.Number2) {
+			break
 		}
 	}
+	return finishBasicProblem(config, problem, random)
+}
+
+// basicProblem builds an addition, subtraction, multiplication or division
+// from two operands. The larger goes first unless subtraction may go
+// negative, and division by zero becomes division by one. Division comes out
+// exact when an operand may be left blank or a word problem shares things
+// out.
+func basicProblem(config models.GameConfig, method models.GameConfigMethod, num1, num2 int) models.GameProblem {
+	if num2 > num1 && !(config.NegativeResults && method == models.GameConfigMethodSubtract) {
+		num1, num2 = num2, num1
+	}
+	if (config.MissingOperand || config.WordProblems != nil) && method == models.GameConfigMethodDivide && num2 != 0 {
+		num1 = num1 * num2
+	}
+
+	var answer int
+	switch method {
+	case models.GameConfigMethodAdd:
+		answer = num1 + num2
+	case models.GameConfigMethodSubtract:
+		answer = num1 - num2
+	case models.GameConfigMethodMultiply:
+		answer = num1 * num2
+	case models.GameConfigMethodDivide:
+		if num2 != 0 {
+			answer = num1 / num2
+		} else {
+			num2 = 1
+			answer = num1
+		}
+	}
+	return models.GameProblem{
+		Number1: num1,
+		Number2: num2,
+		Method:  method,
+		Answer:  models.IntegerValue(answer),
+	}
+}
+
+// finishBasicProblem leaves a slot of problem blank or words it, as the
+// config asks. A blank already picked is kept.
+func finishBasicProblem(config models.GameConfig, problem models.GameProblem, random *rand.Rand) models.GameProblem {
+	if config.MissingOperand && problem.Blank == "" {
+		problem.Blank = pickBlank(problem, random)
+	}
+	if config.WordProblems != nil {
+		problem.Text = renderWordProblem(config, problem, random)
+	}
+	return problem
+}
+
+// listBasicProblems lists every non-trivial problem of a basic method the
+// range gives, once for each slot that could be left blank when operands go
+// missing.
+func listBasicProblems(config models.GameConfig, method models.GameConfigMethod, limit int) []models.GameProblem {
+	pairs := listPairs(config.Range, limit, func(num1, num2 int) (models.GameProblem, bool) {
+		problem := basicProblem(config, method, num1, num2)
+		return problem, !isTrivial(config.Exclude, method, problem.Number1, problem.Number2)
+	})
+	if !config.MissingOperand {
+		return pairs
+	}
 
+	problems := []models.GameProblem{}
+	for _, problem := range pairs {
+		for _, slot := range blankSlots {
+			if canBlank(problem, slot) {
+				problem.Blank = slot
+				problems = append(problems, problem)
+			}
+		}
+	}
 	return problems
 }
+
+// isTrivial reports whether num1 and num2 under method make a problem the
+// exclusions leave out.
+func isTrivial(exclude []models.GameConfigExclusion, method models.GameConfigMethod, num1, num2 int) bool {
+	for _, exclusion := range exclude {
+		switch exclusion {
+		case models.GameConfigExcludeZero:
+			if num1 == 0 || num2 == 0 {
+				return true
+			}
+		case models.GameConfigExcludeOne:
+			if method == models.GameConfigMethodMultiply && (num1 == 1 || num2 == 1) ||
+				method == models.GameConfigMethodDivide && num2 == 1 {
+				return true
+			}
+		case models.GameConfigExcludeSame:
+			if num1 == num2 && (method == models.GameConfigMethodSubtract || method == models.GameConfigMethodDivide) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+var blankSlots = []models.GameProblemSlot{
+	models.GameProblemSlotNumber1,
+	models.GameProblemSlotNumber2,
+	models.GameProblemSlotAnswer,
+}
+
+// pickBlank chooses which slot of problem to leave blank. Operands that
+// would have more than one correct value, like ? × 0 = 0, are never blanked.
+func pickBlank(problem models.GameProblem, random *rand.Rand) models.GameProblemSlot {
+	slot := blankSlots[random.Intn(len(blankSlots))]
+	if !canBlank(problem, slot) {
+		return models.GameProblemSlotAnswer
+	}
+	return slot
+}
+
+// canBlank reports whether slot of problem has only one value that fits.
+func canBlank(problem models.GameProblem, slot models.GameProblemSlot) bool {
+	switch slot {
+	case models.GameProblemSlotNumber1:
+		if problem.Method == models.GameConfigMethodMultiply && problem.Number2 == 0 {
+			return false
+		}
+		if problem.Method == models.GameConfigMethodDivide && problem.Number1 != problem.Answer.Int()*problem.Number2 {
+			return false
+		}
+	case models.GameProblemSlotNumber2:
+		if problem.Method == models.GameConfigMethodMultiply && problem.Number1 == 0 {
+			return false
+		}
+		if problem.Method == models.GameConfigMethodDivide && (problem.Answer.Int() == 0 || problem.Number1 != problem.Answer.Int()*problem.Number2) {
+			return false
+		}
+	}
+	return true
+}
+
+// ExpectedAnswer returns the value the player must give for problem, which is
+// whichever slot is blank.
+func ExpectedAnswer(problem models.GameProblem) models.Value {
+	switch problem.Blank {
+	case models.GameProblemSlotNumber1:
+		return models.IntegerValue(problem.Number1)
+	case models.GameProblemSlotNumber2:
+		return models.IntegerValue(problem.Number2)
+	}
+	return problem.Answer
+}
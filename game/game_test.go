@@ -0,0 +1,102 @@
+package game
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/FiveEightyEight/mwfapi/models"
+)
+
+func TestGenerateGameProblemsSeeded(t *testing.T) {
+	config := models.GameConfig{
+		Methods: []models.GameConfigMethod{
+			models.GameConfigMethodAdd,
+			models.GameConfigMethodDivide,
+			models.GameConfigMethodFraction,
+			models.GameConfigMethodSequence,
+			models.GameConfigMethodTarget,
+		},
+		Range:          models.GameConfigRange{Min: 1, Max: 50},
+		MultipleChoice: true,
+	}
+
+	problems := GenerateGameProblems(config, 42)
+	if !reflect.DeepEqual(problems, GenerateGameProblems(config, 42)) {
+		t.Fatal("expected the same seed to give the same problems")
+	}
+	if reflect.DeepEqual(problems, GenerateGameProblems(config, 43)) {
+		t.Error("expected another seed to give other problems")
+	}
+}
+
+func TestNewSeed(t *testing.T) {
+	for i := 0; i < 1000; i++ {
+		if seed := NewSeed(); seed < 0 || seed > MaxSeed {
+			t.Fatalf("expected a seed from 0 to %d, got %d", MaxSeed, seed)
+		}
+	}
+}
+
+func TestMissingOperand(t *testing.T) {
+	config := models.GameConfig{
+		Methods: []models.GameConfigMethod{
+			models.GameConfigMethodAdd,
+			models.GameConfigMethodSubtract,
+			models.GameConfigMethodMultiply,
+			models.GameConfigMethodDivide,
+		},
+		Range:          models.GameConfigRange{Min: 0, Max: 12},
+		MissingOperand: true,
+	}
+	blanks := map[models.GameProblemSlot]bool{}
+	for seed := int64(0); seed < 50; seed++ {
+		for _, problem := range GenerateGameProblems(config, seed) {
+			blanks[problem.Blank] = true
+			if !canBlank(problem, problem.Blank) {
+				t.Fatalf("seed %d: %s has more than one value for its blank %s", seed, problemKey(problem), problem.Blank)
+			}
+			if problem.Method == models.GameConfigMethodDivide && problem.Number1 != problem.Answer.Int()*problem.Number2 {
+				t.Fatalf("seed %d: expected exact division, got %s", seed, problemKey(problem))
+			}
+		}
+	}
+	for _, slot := range blankSlots {
+		if !blanks[slot] {
+			t.Errorf("expected %s to be left blank at least once", slot)
+		}
+	}
+
+	// 3 + ? = 7
+	problem := models.GameProblem{Number1: 3, Number2: 4, Method: models.GameConfigMethodAdd, Answer: models.IntegerValue(7), Blank: models.GameProblemSlotNumber2}
+	if !CheckAnswer(config, problem, models.IntegerValue(4)) || CheckAnswer(config, problem, models.IntegerValue(7)) {
+		t.Error("expected the blank operand, not the sum, to be the answer")
+	}
+	// ? × 0 = 0 has no single answer
+	zero := models.GameProblem{Number1: 5, Number2: 0, Method: models.GameConfigMethodMultiply, Answer: models.IntegerValue(0)}
+	if canBlank(zero, models.GameProblemSlotNumber1) {
+		t.Error("expected an operand multiplied by zero never to be blanked")
+	}
+}
+
+func TestMethodRanges(t *testing.T) {
+	config := models.GameConfig{
+		Methods: []models.GameConfigMethod{models.GameConfigMethodAdd, models.GameConfigMethodMultiply},
+		Range:   models.GameConfigRange{Min: 1, Max: 9},
+		MethodRanges: map[models.GameConfigMethod]models.GameConfigRange{
+			models.GameConfigMethodMultiply: {Min: 11, Max: 19},
+		},
+	}
+	for seed := int64(0); seed < 50; seed++ {
+		for _, problem := range GenerateGameProblems(config, seed) {
+			want := config.Range
+			if problem.Method == models.GameConfigMethodMultiply {
+				want = config.MethodRanges[problem.Method]
+			}
+			for _, operand := range []int{problem.Number1, problem.Number2} {
+				if operand < want.Min || operand > want.Max {
+					t.Fatalf("seed %d: %s has an operand outside %d to %d", seed, problemKey(problem), want.Min, want.Max)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package game
+
+import "github.com/FiveEightyEight/mwfapi/models"
+
+// HideAnswers returns a copy of problems with their answers cleared, for
+// handing a problem set to a player before they have played it.
+func HideAnswers(problems []models.GameProblem) []models.GameProblem {
+	hidden := make([]models.GameProblem, len(problems))
+	for i, problem := range problems {
+		switch problem.Blank {
+		case models.GameProblemSlotNumber1:
+			problem.Number1 = 0
+		case models.GameProblemSlotNumber2:
+			problem.Number2 = 0
+		default:
+			problem.Answer = models.Value{}
+		}
+		problem.Solution = ""
+		hidden[i] = problem
+	}
+	return hidden
+}
+
+// GradeAnswers marks each answer against the problem at the same index and
+// returns the graded answers with the points earned. Problems without an
+// answer count as wrong, and an answer is correct if it earns any points.
+func GradeAnswers(config models.GameConfig, problems []models.GameProblem, answers []models.AttemptAnswer) ([]models.AttemptAnswer, int) {
+	graded := make([]models.AttemptAnswer, len(answers))
+	points := 0
+	for i, answer := range answers {
+		answer.Points = 0
+		if i < len(problems) {
+			answer.Points, _ = ValidateSubmission(config, problems[i], answer.Submission)
+		}
+		answer.Correct = answer.Points > 0
+		points += answer.Points
+		graded[i] = answer
+	}
+	return graded, points
+}
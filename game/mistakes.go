@@ -0,0 +1,332 @@
+package game
+
+import (
+	"math/big"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/FiveEightyEight/mwfapi/models"
+)
+
+// offByOne nudges answer one step up or down. For a fraction it is the
+// numerator that slips, for a decimal the last digit. A yes/no answer is
+// flipped, a relation moves to its neighbour, such as = for <, and a list
+// loses its last item, or gains a 1 if it has only one.
+func offByOne(answer models.Value, random *rand.Rand) models.Value {
+	switch answer.Kind {
+	case models.ValueKindBoolean:
+		return models.BooleanValue(!answer.Bool())
+	case models.ValueKindRelation:
+		if answer.Numerator != 0 {
+			return models.RelationValue(0)
+		}
+		return models.RelationValue(random.Intn(2)*2 - 1)
+	case models.ValueKindList:
+		if len(answer.Items) > 1 {
+			return models.ListValue(answer.Items[:len(answer.Items)-1]...)
+		}
+		return models.ListValue(append([]models.Value{models.IntegerValue(1)}, answer.Items...)...)
+	}
+	step := int64(1)
+	if random.Intn(2) == 0 {
+		step = -1
+	}
+	if answer.Kind == models.ValueKindFraction && answer.Numerator+step == 0 {
+		step = -step
+	}
+	answer.Numerator += step
+	answer.Mixed = false
+	return answer
+}
+
+// wrongOperation answers the problem as if it used a different method,
+// e.g. adding when asked to multiply.
+func wrongOperation(problem models.GameProblem, random *rand.Rand) models.Value {
+	if problem.Expression != nil {
+		if answer, ok := ignorePrecedence(problem.Expression); ok {
+			return models.IntegerValue(answer)
+		}
+		return offByOne(problem.Answer, random)
+	}
+	switch problem.Method {
+	case models.GameConfigMethodFraction:
+		if answer, ok := fractionMistake(problem); ok {
+			return answer
+		}
+		return offByOne(problem.Answer, random)
+	case models.GameConfigMethodDecimal, models.GameConfigMethodPercentOf, models.GameConfigMethodPercentRatio:
+		if answer, ok := decimalMistake(problem, random); ok {
+			return answer
+		}
+		return offByOne(problem.Answer, random)
+	case models.GameConfigMethodPower, models.GameConfigMethodSquare, models.GameConfigMethodCube,
+		models.GameConfigMethodSquareRoot, models.GameConfigMethodPowerOfTen:
+		if answer, ok := powerMistake(problem, random); ok {
+			return answer
+		}
+		return offByOne(problem.Answer, random)
+	case models.GameConfigMethodCompare:
+		return swapDigits(problem.Answer, random)
+	case models.GameConfigMethodEstimate:
+		return misplaceMagnitude(problem.Answer, random)
+	case models.GameConfigMethodSequence:
+		if answer, ok := sequenceMistake(problem); ok {
+			return answer
+		}
+		return offByOne(problem.Answer, random)
+	case models.GameConfigMethodGCD, models.GameConfigMethodLCM, models.GameConfigMethodIsPrime, models.GameConfigMethodPrimeFactors:
+		if answer, ok := numberTheoryMistake(problem); ok {
+			return answer
+		}
+		return offByOne(problem.Answer, random)
+	case models.GameConfigMethodFactorPair:
+		// Two numbers that add up to it instead
+		return factorList([]int{problem.Number1 / 2, problem.Number1 - problem.Number1/2})
+	}
+
+	methods := []models.GameConfigMethod{
+		models.GameConfigMethodAdd,
+		models.GameConfigMethodSubtract,
+		models.GameConfigMethodMultiply,
+		models.GameConfigMethodDivide,
+	}
+	for _, i := range random.Perm(len(methods)) {
+		if methods[i] == problem.Method {
+			continue
+		}
+		answer, ok := applyMethod(methods[i], problem.Number1, problem.Number2)
+		if ok && answer != problem.Answer.Int() {
+			return models.IntegerValue(answer)
+		}
+	}
+	return offByOne(problem.Answer, random)
+}
+
+// fractionMistake applies the operation straight across numerators and
+// denominators, e.g. 1/2 + 1/3 = 2/5, or divides without flipping the divisor.
+func fractionMistake(problem models.GameProblem) (models.Value, bool) {
+	a, b := problem.Operands[0], problem.Operands[1]
+	var mistake models.Value
+	switch problem.Operation {
+	case models.GameConfigMethodAdd:
+		mistake = models.FractionValue(a.Numerator+b.Numerator, a.Denominator+b.Denominator)
+	case models.GameConfigMethodSubtract:
+		if a.Denominator == b.Denominator {
+			return models.Value{}, false
+		}
+		mistake = models.FractionValue(abs64(a.Numerator-b.Numerator), abs64(a.Denominator-b.Denominator))
+	case models.GameConfigMethodMultiply:
+		result, _ := applyRat(models.GameConfigMethodAdd, ratOf(a), ratOf(b))
+		return valueOfRat(result), true
+	case models.GameConfigMethodDivide:
+		mistake = models.FractionValue(a.Numerator*b.Numerator, a.Denominator*b.Denominator)
+	}
+	if mistake.Denominator == 0 || ratOf(mistake).Cmp(ratOf(problem.Answer)) == 0 {
+		return models.Value{}, false
+	}
+	return mistake, true
+}
+
+// ignorePrecedence evaluates an expression strictly left to right, the
+// classic order of operations mistake. It reports false if that gives the
+// same answer or does not come out whole.
+func ignorePrecedence(expr *models.Expression) (int, bool) {
+	text := RenderExpression(expr)
+	if strings.Contains(text, "(") {
+		return 0, false
+	}
+
+	tokens := strings.Fields(text)
+	total, _ := strconv.Atoi(tokens[0])
+	for i := 1; i+1 < len(tokens); i += 2 {
+		operand, _ := strconv.Atoi(tokens[i+1])
+		var operator models.GameConfigMethod
+		for method, symbol := range operatorSymbols {
+			if symbol == tokens[i] {
+				operator = method
+			}
+		}
+		if operator == models.GameConfigMethodDivide && (operand == 0 || total%operand != 0) {
+			return 0, false
+		}
+		total, _ = applyMethod(operator, total, operand)
+	}
+
+	answer, _ := EvaluateExpression(expr)
+	return total, total != answer
+}
+
+// decimalMistake applies the wrong operation to a decimal problem's operands,
+// or forgets the factor of 100 in a percentage.
+func decimalMistake(problem models.GameProblem, random *rand.Rand) (models.Value, bool) {
+	a, b := ratOf(problem.Operands[0]), ratOf(problem.Operands[1])
+	var mistake *big.Rat
+	switch problem.Method {
+	case models.GameConfigMethodPercentOf:
+		mistake = new(big.Rat).Mul(a, b)
+	case models.GameConfigMethodPercentRatio:
+		mistake = new(big.Rat).Quo(a, b)
+	default:
+		operations := []models.GameConfigMethod{
+			models.GameConfigMethodAdd,
+			models.GameConfigMethodSubtract,
+			models.GameConfigMethodMultiply,
+		}
+		operation := operations[random.Intn(len(operations))]
+		if operation == problem.Operation {
+			return models.Value{}, false
+		}
+		mistake, _ = applyRat(operation, a, b)
+	}
+	answer, ok := decimalOfRat(mistake, MaxDecimalPlaces*2)
+	if !ok || mistake.Cmp(ratOf(problem.Answer)) == 0 {
+		return models.Value{}, false
+	}
+	return answer, true
+}
+
+// powerMistake multiplies base by exponent instead of raising it, halves
+// instead of taking the root, or miscounts the zeros of a power of ten.
+func powerMistake(problem models.GameProblem, random *rand.Rand) (models.Value, bool) {
+	var mistake models.Value
+	switch problem.Method {
+	case models.GameConfigMethodSquareRoot:
+		if problem.Number1%2 != 0 {
+			return models.Value{}, false
+		}
+		mistake = models.IntegerValue(problem.Number1 / 2)
+	case models.GameConfigMethodPowerOfTen:
+		shifted := new(big.Rat).Mul(ratOf(problem.Answer), big.NewRat(10, 1))
+		if random.Intn(2) == 0 {
+			shifted.Quo(ratOf(problem.Answer), big.NewRat(10, 1))
+		}
+		mistake, _ = decimalOfRat(shifted, MaxExponent+1)
+		if shifted.IsInt() {
+			mistake = valueOfRat(shifted)
+		}
+	default:
+		mistake = models.IntegerValue(problem.Number1 * problem.Number2)
+	}
+	if ratOf(mistake).Cmp(ratOf(problem.Answer)) == 0 {
+		return models.Value{}, false
+	}
+	return mistake, true
+}
+
+// numberTheoryMistake mixes up GCD and LCM, takes the plain product for an
+// LCM, judges a number prime by its last digit, or leaves repeated factors
+// out of a factorization.
+func numberTheoryMistake(problem models.GameProblem) (models.Value, bool) {
+	var mistake models.Value
+	switch problem.Method {
+	case models.GameConfigMethodGCD:
+		mistake = models.IntegerValue(lcm(problem.Number1, problem.Number2))
+	case models.GameConfigMethodLCM:
+		mistake = models.IntegerValue(problem.Number1 * problem.Number2)
+	case models.GameConfigMethodIsPrime:
+		last := problem.Number1 % 10
+		looksPrime := last != 0 && last != 2 && last != 4 && last != 5 && last != 6 && last != 8
+		return models.BooleanValue(looksPrime), looksPrime != problem.Answer.Bool()
+	case models.GameConfigMethodPrimeFactors:
+		var distinct []models.Value
+		for i, factor := range problem.Answer.Items {
+			if i == 0 || factor.Numerator != problem.Answer.Items[i-1].Numerator {
+				distinct = append(distinct, factor)
+			}
+		}
+		return models.ListValue(distinct...), len(distinct) != len(problem.Answer.Items)
+	}
+	return mistake, ratOf(mistake).Cmp(ratOf(problem.Answer)) != 0
+}
+
+// sequenceMistake carries on the difference between the two terms next to
+// the missing one, as if every sequence were arithmetic.
+func sequenceMistake(problem models.GameProblem) (models.Value, bool) {
+	terms, i := problem.Terms, missingTerm(problem)
+	var guess int
+	switch {
+	case i >= 2:
+		guess = 2*terms[i-1].Int() - terms[i-2].Int()
+	case i+2 < len(terms):
+		guess = 2*terms[i+1].Int() - terms[i+2].Int()
+	default:
+		return models.Value{}, false
+	}
+	return models.IntegerValue(guess), guess != problem.Answer.Int()
+}
+
+// misplaceMagnitude rounds answer to a whole number but gets it a factor of
+// ten too big or too small, the usual way an estimate goes badly wrong.
+func misplaceMagnitude(answer models.Value, random *rand.Rand) models.Value {
+	rounded := new(big.Int).Quo(ratOf(answer).Num(), ratOf(answer).Denom())
+	if random.Intn(2) == 0 && rounded.CmpAbs(big.NewInt(10)) >= 0 {
+		return models.IntegerValue(int(rounded.Int64() / 10))
+	}
+	return models.IntegerValue(int(rounded.Int64() * 10))
+}
+
+// swapDigits swaps two neighbouring digits of answer, e.g. 54 for 45. A
+// fraction is flipped upside down instead, a decimal has its point slip and
+// < and > are mixed up.
+func swapDigits(answer models.Value, random *rand.Rand) models.Value {
+	if answer.Kind == models.ValueKindRelation && answer.Numerator != 0 {
+		return models.RelationValue(int(-answer.Numerator))
+	}
+	if !answer.IsNumber() {
+		return offByOne(answer, random)
+	}
+	if answer.Kind == models.ValueKindDecimal {
+		answer.Numerator *= 10
+		return answer
+	}
+	if answer.Kind == models.ValueKindFraction {
+		if answer.Numerator == 0 {
+			return offByOne(answer, random)
+		}
+		return models.FractionValue(answer.Denominator, answer.Numerator)
+	}
+
+	n := answer.Int()
+	digits := []byte(strconv.Itoa(abs(n)))
+	var candidates []int
+	for i := 0; i+1 < len(digits); i++ {
+		if digits[i] != digits[i+1] && !(i == 0 && digits[i+1] == '0') {
+			candidates = append(candidates, i)
+		}
+	}
+	if len(candidates) == 0 {
+		return offByOne(answer, random)
+	}
+	i := candidates[random.Intn(len(candidates))]
+	digits[i], digits[i+1] = digits[i+1], digits[i]
+	swapped, _ := strconv.Atoi(string(digits))
+	if n < 0 {
+		return models.IntegerValue(-swapped)
+	}
+	return models.IntegerValue(swapped)
+}
+
+func applyMethod(method models.GameConfigMethod, num1, num2 int) (int, bool) {
+	switch method {
+	case models.GameConfigMethodAdd:
+		return num1 + num2, true
+	case models.GameConfigMethodSubtract:
+		return num1 - num2, true
+	case models.GameConfigMethodMultiply:
+		return num1 * num2, true
+	case models.GameConfigMethodDivide:
+		if num2 == 0 {
+			return 0, false
+		}
+		return num1 / num2, true
+	}
+	return 0, false
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
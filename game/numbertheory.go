@@ -0,0 +1,275 @@
+package game
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+	"sort"
+
+	"github.com/FiveEightyEight/mwfapi/models"
+)
+
+const numberTheoryAttempts = 20
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return abs(a)
+}
+
+func lcm(a, b int) int {
+	return a / gcd(a, b) * b
+}
+
+func isPrime(n int) bool {
+	if n < 2 {
+		return false
+	}
+	for d := 2; d*d <= n; d++ {
+		if n%d == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// primeFactors lists the prime factors of n in ascending order, repeats included.
+func primeFactors(n int) []int {
+	var factors []int
+	for d := 2; d*d <= n; d++ {
+		for n%d == 0 {
+			factors = append(factors, d)
+			n /= d
+		}
+	}
+	if n > 1 {
+		factors = append(factors, n)
+	}
+	return factors
+}
+
+func factorList(factors []int) models.Value {
+	items := make([]models.Value, len(factors))
+	for i, factor := range factors {
+		items[i] = models.IntegerValue(factor)
+	}
+	return models.ListValue(items...)
+}
+
+// numberTheoryRange is the config's range with anything below 2 cut off.
+func numberTheoryRange(config models.GameConfig) models.GameConfigRange {
+	return models.GameConfigRange{Min: max(config.Range.Min, 2), Max: config.Range.Max}
+}
+
+// generateGCDProblem builds both numbers from a shared factor so the answer
+// is rarely just 1.
+func generateGCDProblem(config models.GameConfig, random *rand.Rand) models.GameProblem {
+	r := numberTheoryRange(config)
+	factor := 1
+	if r.Max >= 4 {
+		factor = random.Intn(r.Max/2-1) + 2
+	}
+	multiples := models.GameConfigRange{Min: max((r.Min+factor-1)/factor, 1), Max: r.Max / factor}
+	if multiples.Max < multiples.Min {
+		factor, multiples = 1, r
+	}
+
+	return gcdProblem(factor*randomIn(multiples, random), factor*randomIn(multiples, random))
+}
+
+func gcdProblem(num1, num2 int) models.GameProblem {
+	return models.GameProblem{
+		Number1: num1,
+		Number2: num2,
+		Method:  models.GameConfigMethodGCD,
+		Answer:  models.IntegerValue(gcd(num1, num2)),
+		Text:    fmt.Sprintf("GCD(%d, %d)", num1, num2),
+	}
+}
+
+// listGCDProblems lists every pair from the range, keeping to pairs with a
+// shared factor, as generateGCDProblem does, when the range has any.
+func listGCDProblems(config models.GameConfig, _ models.GameConfigMethod, limit int) []models.GameProblem {
+	r := numberTheoryRange(config)
+	return listPairs(r, limit, func(num1, num2 int) (models.GameProblem, bool) {
+		return gcdProblem(num1, num2), r.Max < 4 || gcd(num1, num2) > 1
+	})
+}
+
+func generateLCMProblem(config models.GameConfig, random *rand.Rand) models.GameProblem {
+	r := numberTheoryRange(config)
+	return lcmProblem(randomIn(r, random), randomIn(r, random))
+}
+
+func lcmProblem(num1, num2 int) models.GameProblem {
+	return models.GameProblem{
+		Number1: num1,
+		Number2: num2,
+		Method:  models.GameConfigMethodLCM,
+		Answer:  models.IntegerValue(lcm(num1, num2)),
+		Text:    fmt.Sprintf("LCM(%d, %d)", num1, num2),
+	}
+}
+
+func listLCMProblems(config models.GameConfig, _ models.GameConfigMethod, limit int) []models.GameProblem {
+	return listPairs(numberTheoryRange(config), limit, func(num1, num2 int) (models.GameProblem, bool) {
+		return lcmProblem(num1, num2), true
+	})
+}
+
+// generateIsPrimeProblem asks whether a number is prime, picking a prime
+// about half the time. Non-primes are odd where possible so they aren't
+// given away by their last digit.
+func generateIsPrimeProblem(config models.GameConfig, random *rand.Rand) models.GameProblem {
+	r := numberTheoryRange(config)
+	wantPrime := random.Intn(2) == 0
+
+	n := randomIn(r, random)
+	for attempt := 0; attempt < numberTheoryAttempts; attempt++ {
+		if isPrime(n) == wantPrime && (wantPrime || n%2 != 0) {
+			break
+		}
+		n = randomIn(r, random)
+	}
+	return isPrimeProblem(n)
+}
+
+func isPrimeProblem(n int) models.GameProblem {
+	return models.GameProblem{
+		Number1: n,
+		Method:  models.GameConfigMethodIsPrime,
+		Answer:  models.BooleanValue(isPrime(n)),
+		Text:    fmt.Sprintf("Is %d prime?", n),
+	}
+}
+
+func listIsPrimeProblems(config models.GameConfig, _ models.GameConfigMethod, limit int) []models.GameProblem {
+	return listNumbers(numberTheoryRange(config), limit, func(n int) (models.GameProblem, bool) {
+		return isPrimeProblem(n), true
+	})
+}
+
+// generatePrimeFactorsProblem asks for the prime factorization of a number,
+// preferring ones with at least three factors.
+func generatePrimeFactorsProblem(config models.GameConfig, random *rand.Rand) models.GameProblem {
+	r := numberTheoryRange(config)
+
+	n := randomIn(r, random)
+	for attempt := 0; attempt < numberTheoryAttempts && len(primeFactors(n)) < 3; attempt++ {
+		n = randomIn(r, random)
+	}
+	return primeFactorsProblem(n)
+}
+
+func primeFactorsProblem(n int) models.GameProblem {
+	return models.GameProblem{
+		Number1: n,
+		Method:  models.GameConfigMethodPrimeFactors,
+		Answer:  factorList(primeFactors(n)),
+		Text:    fmt.Sprintf("Prime factors of %d", n),
+	}
+}
+
+func listPrimeFactorsProblems(config models.GameConfig, _ models.GameConfigMethod, limit int) []models.GameProblem {
+	return listNumbers(numberTheoryRange(config), limit, func(n int) (models.GameProblem, bool) {
+		return primeFactorsProblem(n), true
+	})
+}
+
+// factorPairFields are the fields a factor pair is answered with.
+var factorPairFields = []string{"first", "second"}
+
+// generateFactorPairProblem asks for two numbers that multiply to a
+// composite number. Any pair is accepted, the answer kept is the one
+// closest to its square root.
+func generateFactorPairProblem(config models.GameConfig, random *rand.Rand) models.GameProblem {
+	r := models.GameConfigRange{Min: max(config.Range.Min, 4), Max: config.Range.Max}
+
+	n := randomIn(r, random)
+	for attempt := 0; attempt < numberTheoryAttempts && isPrime(n); attempt++ {
+		n = randomIn(r, random)
+	}
+	return factorPairProblem(n)
+}
+
+func factorPairProblem(n int) models.GameProblem {
+	first := 1
+	for d := 2; d*d <= n; d++ {
+		if n%d == 0 {
+			first = d
+		}
+	}
+	return models.GameProblem{
+		Number1: n,
+		Method:  models.GameConfigMethodFactorPair,
+		Answer:  factorList([]int{first, n / first}),
+		Fields:  factorPairFields,
+		Text:    fmt.Sprintf("Give two numbers whose product is %d", n),
+	}
+}
+
+// listFactorPairProblems lists the composite numbers in the range, leaving
+// out the primes generateFactorPairProblem avoids.
+func listFactorPairProblems(config models.GameConfig, _ models.GameConfigMethod, limit int) []models.GameProblem {
+	r := models.GameConfigRange{Min: max(config.Range.Min, 4), Max: config.Range.Max}
+	return listNumbers(r, limit, func(n int) (models.GameProblem, bool) {
+		return factorPairProblem(n), !isPrime(n)
+	})
+}
+
+// listNumbers builds a problem from each number in r that build keeps, up
+// to limit of them.
+func listNumbers(r models.GameConfigRange, limit int, build func(n int) (models.GameProblem, bool)) []models.GameProblem {
+	problems := []models.GameProblem{}
+	for n := r.Min; n <= r.Max && len(problems) < limit; n++ {
+		if problem, ok := build(n); ok {
+			problems = append(problems, problem)
+		}
+	}
+	return problems
+}
+
+// listPairs builds a problem from each ordered pair of numbers in r that
+// build keeps, up to limit of them.
+func listPairs(r models.GameConfigRange, limit int, build func(num1, num2 int) (models.GameProblem, bool)) []models.GameProblem {
+	problems := []models.GameProblem{}
+	for num1 := r.Min; num1 <= r.Max && len(problems) < limit; num1++ {
+		for num2 := r.Min; num2 <= r.Max; num2++ {
+			if problem, ok := build(num1, num2); ok {
+				problems = append(problems, problem)
+			}
+		}
+	}
+	return problems
+}
+
+// sameItems reports whether two lists hold the same numbers the same number
+// of times, in any order. A lone number counts as a list of one.
+func sameItems(a, b models.Value) bool {
+	itemsOf := func(v models.Value) []*big.Rat {
+		if v.IsNumber() {
+			return []*big.Rat{ratOf(v)}
+		}
+		rats := make([]*big.Rat, len(v.Items))
+		for i, item := range v.Items {
+			rats[i] = ratOf(item)
+		}
+		sort.Slice(rats, func(i, j int) bool { return rats[i].Cmp(rats[j]) < 0 })
+		return rats
+	}
+
+	if a.Kind != models.ValueKindList && !a.IsNumber() || b.Kind != models.ValueKindList && !b.IsNumber() {
+		return false
+	}
+	itemsA, itemsB := itemsOf(a), itemsOf(b)
+	if len(itemsA) != len(itemsB) {
+		return false
+	}
+	for i := range itemsA {
+		if itemsA[i].Cmp(itemsB[i]) != 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,78 @@
+package game
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/FiveEightyEight/mwfapi/models"
+)
+
+func TestNumberTheory(t *testing.T) {
+	if got := gcd(84, 36); got != 12 {
+		t.Errorf("GCD(84, 36): expected 12, got %d", got)
+	}
+	if got := lcm(4, 6); got != 12 {
+		t.Errorf("LCM(4, 6): expected 12, got %d", got)
+	}
+	for n, want := range map[int]bool{0: false, 1: false, 2: true, 9: false, 91: false, 97: true} {
+		if got := isPrime(n); got != want {
+			t.Errorf("isPrime(%d): expected %v, got %v", n, want, got)
+		}
+	}
+	if got := primeFactors(360); !slices.Equal(got, []int{2, 2, 2, 3, 3, 5}) {
+		t.Errorf("prime factors of 360: got %v", got)
+	}
+	if got := factorPairProblem(36).Answer; !sameItems(got, factorList([]int{6, 6})) {
+		t.Errorf("expected the pair of 36 closest to its root, got %s", got)
+	}
+}
+
+func TestGenerateNumberTheoryProblems(t *testing.T) {
+	config := models.GameConfig{
+		Methods: []models.GameConfigMethod{
+			models.GameConfigMethodGCD,
+			models.GameConfigMethodLCM,
+			models.GameConfigMethodIsPrime,
+			models.GameConfigMethodPrimeFactors,
+		},
+		Range: models.GameConfigRange{Min: 0, Max: 60},
+	}
+	primes, composites := 0, 0
+	for seed := int64(0); seed < 50; seed++ {
+		for _, problem := range GenerateGameProblems(config, seed) {
+			if problem.Number1 < 2 || problem.Number1 > 60 {
+				t.Fatalf("seed %d: %s is outside 2 to 60", seed, problem.Text)
+			}
+			switch problem.Method {
+			case models.GameConfigMethodGCD:
+				if problem.Answer.Int() == 1 {
+					t.Fatalf("seed %d: %s shares no factor", seed, problem.Text)
+				}
+			case models.GameConfigMethodLCM:
+				if answer := problem.Answer.Int(); answer%problem.Number1 != 0 || answer%problem.Number2 != 0 {
+					t.Fatalf("seed %d: %s = %d is not a common multiple", seed, problem.Text, answer)
+				}
+			case models.GameConfigMethodIsPrime:
+				if problem.Answer.Bool() {
+					primes++
+				} else {
+					composites++
+				}
+			case models.GameConfigMethodPrimeFactors:
+				product := 1
+				for _, factor := range problem.Answer.Items {
+					if !isPrime(factor.Int()) {
+						t.Fatalf("seed %d: %s lists %d, which isn't prime", seed, problem.Text, factor.Int())
+					}
+					product *= factor.Int()
+				}
+				if product != problem.Number1 {
+					t.Fatalf("seed %d: %s multiplies to %d", seed, problem.Text, product)
+				}
+			}
+		}
+	}
+	if primes == 0 || composites == 0 {
+		t.Errorf("expected primes and non-primes, got %d and %d", primes, composites)
+	}
+}
@@ -0,0 +1,129 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"unicode"
+
+	"github.com/FiveEightyEight/mwfapi/models"
+)
+
+// MaxExpressionLength bounds the text ParseExpression accepts.
+const MaxExpressionLength = 200
+
+var operatorTokens = map[rune]models.GameConfigMethod{
+	'+': models.GameConfigMethodAdd,
+	'-': models.GameConfigMethodSubtract,
+	'*': models.GameConfigMethodMultiply,
+	'x': models.GameConfigMethodMultiply,
+	'×': models.GameConfigMethodMultiply,
+	'/': models.GameConfigMethodDivide,
+	'÷': models.GameConfigMethodDivide,
+}
+
+type expressionParser struct {
+	text []rune
+	pos  int
+}
+
+// ParseExpression reads an arithmetic expression over whole numbers, such as
+// "(25 + 50) × 3 - 7", into a tree. Multiplication may be written *, x or ×
+// and division / or ÷.
+func ParseExpression(text string) (*models.Expression, error) {
+	if len(text) > MaxExpressionLength {
+		return nil, fmt.Errorf("expression is longer than %d characters", MaxExpressionLength)
+	}
+	p := expressionParser{text: []rune(text)}
+	expr, err := p.sum()
+	if err != nil {
+		return nil, err
+	}
+	if r, ok := p.peek(); ok {
+		return nil, fmt.Errorf("unexpected %q", r)
+	}
+	return expr, nil
+}
+
+// peek skips whitespace and returns the next character, if there is one.
+func (p *expressionParser) peek() (rune, bool) {
+	for p.pos < len(p.text) && unicode.IsSpace(p.text[p.pos]) {
+		p.pos++
+	}
+	if p.pos == len(p.text) {
+		return 0, false
+	}
+	return p.text[p.pos], true
+}
+
+// sum parses terms joined by + and -.
+func (p *expressionParser) sum() (*models.Expression, error) {
+	return p.binary(p.product, 1)
+}
+
+// product parses factors joined by × and ÷.
+func (p *expressionParser) product() (*models.Expression, error) {
+	return p.binary(p.factor, 2)
+}
+
+// binary parses operands joined left to right by operators of the given precedence.
+func (p *expressionParser) binary(operand func() (*models.Expression, error), level int) (*models.Expression, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		r, ok := p.peek()
+		operator, isOperator := operatorTokens[r]
+		if !ok || !isOperator || precedence(operator) != level {
+			return left, nil
+		}
+		p.pos++
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		left = &models.Expression{Operator: operator, Left: left, Right: right}
+	}
+}
+
+// factor parses a number or a parenthesized expression.
+func (p *expressionParser) factor() (*models.Expression, error) {
+	r, ok := p.peek()
+	if !ok {
+		return nil, errors.New("unexpected end of expression")
+	}
+	if r == '(' {
+		p.pos++
+		expr, err := p.sum()
+		if err != nil {
+			return nil, err
+		}
+		if r, ok := p.peek(); !ok || r != ')' {
+			return nil, errors.New("missing )")
+		}
+		p.pos++
+		return expr, nil
+	}
+
+	start := p.pos
+	for p.pos < len(p.text) && unicode.IsDigit(p.text[p.pos]) {
+		p.pos++
+	}
+	if start == p.pos {
+		return nil, fmt.Errorf("unexpected %q", r)
+	}
+	n, err := strconv.Atoi(string(p.text[start:p.pos]))
+	if err != nil {
+		return nil, fmt.Errorf("invalid number %q", string(p.text[start:p.pos]))
+	}
+	return &models.Expression{Value: &n}, nil
+}
+
+// expressionLeaves lists the numbers expr is built from.
+func expressionLeaves(expr *models.Expression) []int {
+	if expr.Value != nil {
+		return []int{*expr.Value}
+	}
+	return append(expressionLeaves(expr.Left), expressionLeaves(expr.Right)...)
+}
@@ -0,0 +1,42 @@
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseExpression(t *testing.T) {
+	tests := []struct {
+		text  string
+		value int
+	}{
+		{"7", 7},
+		{"2 + 3 * 4", 14},
+		{"(2 + 3) x 4", 20},
+		{"(25 + 50) × 3 - 7", 218},
+		{"100 / 4 ÷ 5", 5},
+		{"10 - 4 - 3", 3},
+		{"  ( ( 6 ) )  ", 6},
+	}
+	for _, test := range tests {
+		expr, err := ParseExpression(test.text)
+		if err != nil {
+			t.Errorf("%q: %v", test.text, err)
+			continue
+		}
+		if value, ok := EvaluateExpression(expr); !ok || value != test.value {
+			t.Errorf("%q: expected %d, got %d, %v", test.text, test.value, value, ok)
+		}
+		// Rendering and parsing again gives the same value
+		again, err := ParseExpression(RenderExpression(expr))
+		if value, _ := EvaluateExpression(again); err != nil || value != test.value {
+			t.Errorf("%q: rendered as %q, which comes to %d, %v", test.text, RenderExpression(expr), value, err)
+		}
+	}
+
+	for _, text := range []string{"", "2 +", "(2 + 3", "2 + 3)", "2 ^ 3", "-2 + 5", "2.5 × 2", strings.Repeat("1+", MaxExpressionLength)} {
+		if _, err := ParseExpression(text); err == nil {
+			t.Errorf("%q: expected an error", text)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package game
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/FiveEightyEight/mwfapi/models"
+)
+
+const (
+	MaxPowerBase   = 1000
+	MaxExponent    = 9
+	MaxPowerResult = 1000000
+)
+
+// Each method has its own default operand ranges, sized so the answers stay
+// reasonable to work out by hand.
+var (
+	defaultPowerBases          = models.GameConfigRange{Min: 2, Max: 10}
+	defaultPowerExponents      = models.GameConfigRange{Min: 0, Max: 5}
+	defaultSquareBases         = models.GameConfigRange{Min: 1, Max: 20}
+	defaultCubeBases           = models.GameConfigRange{Min: 1, Max: 10}
+	defaultSquareRoots         = models.GameConfigRange{Min: 1, Max: 15}
+	defaultPowerOfTenExponents = models.GameConfigRange{Min: 0, Max: 6}
+)
+
+var superscriptDigits = strings.NewReplacer(
+	"-", "⁻", "0", "⁰", "1", "¹", "2", "²", "3", "³", "4", "⁴",
+	"5", "⁵", "6", "⁶", "7", "⁷", "8", "⁸", "9", "⁹",
+)
+
+func powerSettings(config models.GameConfig) models.GameConfigPowers {
+	if config.Powers == nil {
+		return models.GameConfigPowers{}
+	}
+	return *config.Powers
+}
+
+func rangeOr(r *models.GameConfigRange, fallback models.GameConfigRange) models.GameConfigRange {
+	if r == nil {
+		return fallback
+	}
+	return *r
+}
+
+func randomIn(r models.GameConfigRange, random *rand.Rand) int {
+	return random.Intn(r.Max-r.Min+1) + r.Min
+}
+
+// randomExponent draws an exponent and, when the config allows it, makes it
+// negative half of the time.
+func randomExponent(settings models.GameConfigPowers, fallback models.GameConfigRange, random *rand.Rand) int {
+	exponent := randomIn(rangeOr(settings.Exponents, fallback), random)
+	if settings.NegativeExponents && exponent > 0 && random.Intn(2) == 0 {
+		return -exponent
+	}
+	return exponent
+}
+
+// power raises base to exponent exactly. A negative exponent gives a
+// fraction, so base must not be zero then.
+func power(base, exponent int) *big.Rat {
+	result := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(int64(abs(base))), big.NewInt(int64(abs(exponent))), nil))
+	if base < 0 && exponent%2 != 0 {
+		result.Neg(result)
+	}
+	if exponent < 0 {
+		result.Inv(result)
+	}
+	return result
+}
+
+// generatePowerProblem builds base^exponent for power, square and cube. The
+// base and exponent go in Number1 and Number2.
+func generatePowerProblem(method models.GameConfigMethod, config models.GameConfig, random *rand.Rand) models.GameProblem {
+	settings := powerSettings(config)
+	switch method {
+	case models.GameConfigMethodSquare:
+		return powerProblem(method, randomIn(rangeOr(settings.Bases, defaultSquareBases), random), 2)
+	case models.GameConfigMethodCube:
+		return powerProblem(method, randomIn(rangeOr(settings.Bases, defaultCubeBases), random), 3)
+	}
+	base := randomIn(rangeOr(settings.Bases, defaultPowerBases), random)
+	return powerProblem(method, base, randomExponent(settings, defaultPowerExponents, random))
+}
+
+func powerProblem(method models.GameConfigMethod, base, exponent int) models.GameProblem {
+	// 0⁰ is undefined and 0⁻ⁿ divides by zero
+	if base == 0 && exponent <= 0 {
+		exponent = max(-exponent, 1)
+	}
+	return models.GameProblem{
+		Number1: base,
+		Number2: exponent,
+		Method:  method,
+		Answer:  valueOfRat(power(base, exponent)),
+		Text:    renderPower(base, exponent),
+	}
+}
+
+// listPowerProblems lists every power, square or cube the config's bases
+// and exponents give.
+func listPowerProblems(config models.GameConfig, method models.GameConfigMethod, limit int) []models.GameProblem {
+	settings := powerSettings(config)
+	bases, exponents := rangeOr(settings.Bases, defaultPowerBases), exponentsOf(settings, defaultPowerExponents)
+	switch method {
+	case models.GameConfigMethodSquare:
+		bases, exponents = rangeOr(settings.Bases, defaultSquareBases), []int{2}
+	case models.GameConfigMethodCube:
+		bases, exponents = rangeOr(settings.Bases, defaultCubeBases), []int{3}
+	}
+
+	problems := []models.GameProblem{}
+	for base := bases.Min; base <= bases.Max && len(problems) < limit; base++ {
+		for _, exponent := range exponents {
+			problems = append(problems, powerProblem(method, base, exponent))
+		}
+	}
+	return problems
+}
+
+// exponentsOf lists every exponent randomExponent can draw.
+func exponentsOf(settings models.GameConfigPowers, fallback models.GameConfigRange) []int {
+	r := rangeOr(settings.Exponents, fallback)
+	exponents := []int{}
+	for exponent := r.Min; exponent <= r.Max; exponent++ {
+		exponents = append(exponents, exponent)
+		if settings.NegativeExponents && exponent > 0 {
+			exponents = append(exponents, -exponent)
+		}
+	}
+	return exponents
+}
+
+// generateSquareRootProblem builds √n for a perfect square n, held in Number1.
+func generateSquareRootProblem(config models.GameConfig, random *rand.Rand) models.GameProblem {
+	return squareRootProblem(randomIn(rangeOr(powerSettings(config).Roots, defaultSquareRoots), random))
+}
+
+func squareRootProblem(root int) models.GameProblem {
+	return models.GameProblem{
+		Number1: root * root,
+		Number2: 2,
+		Method:  models.GameConfigMethodSquareRoot,
+		Answer:  models.IntegerValue(root),
+		Text:    fmt.Sprintf("√%d", root*root),
+	}
+}
+
+func listSquareRootProblems(config models.GameConfig, _ models.GameConfigMethod, limit int) []models.GameProblem {
+	roots := rangeOr(powerSettings(config).Roots, defaultSquareRoots)
+	problems := []models.GameProblem{}
+	for root := roots.Min; root <= roots.Max && len(problems) < limit; root++ {
+		problems = append(problems, squareRootProblem(root))
+	}
+	return problems
+}
+
+// generatePowerOfTenProblem builds a single digit times a power of ten, such as
+// 7 × 10³ or, with negative exponents, 7 × 10⁻² = 0.07.
+func generatePowerOfTenProblem(config models.GameConfig, random *rand.Rand) models.GameProblem {
+	digit := random.Intn(9) + 1
+	return powerOfTenProblem(digit, randomExponent(powerSettings(config), defaultPowerOfTenExponents, random))
+}
+
+func powerOfTenProblem(digit, exponent int) models.GameProblem {
+	answer := models.DecimalValue(int64(digit), -exponent)
+	if exponent >= 0 {
+		answer = valueOfRat(new(big.Rat).Mul(big.NewRat(int64(digit), 1), power(10, exponent)))
+	}
+	return models.GameProblem{
+		Number1: digit,
+		Number2: exponent,
+		Method:  models.GameConfigMethodPowerOfTen,
+		Answer:  answer,
+		Text:    fmt.Sprintf("%d × %s", digit, renderPower(10, exponent)),
+	}
+}
+
+func listPowerOfTenProblems(config models.GameConfig, _ models.GameConfigMethod, limit int) []models.GameProblem {
+	problems := []models.GameProblem{}
+	for _, exponent := range exponentsOf(powerSettings(config), defaultPowerOfTenExponents) {
+		for digit := 1; digit <= 9 && len(problems) < limit; digit++ {
+			problems = append(problems, powerOfTenProblem(digit, exponent))
+		}
+	}
+	return problems
+}
+
+func renderPower(base, exponent int) string {
+	text := strconv.Itoa(base)
+	if base < 0 {
+		text = "(" + text + ")"
+	}
+	return text + superscriptDigits.Replace(strconv.Itoa(exponent))
+}
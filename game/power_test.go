@@ -0,0 +1,87 @@
+package game
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/FiveEightyEight/mwfapi/models"
+)
+
+func TestPowerProblems(t *testing.T) {
+	tests := []struct {
+		name    string
+		problem models.GameProblem
+		text    string
+		answer  *big.Rat
+	}{
+		{"power", powerProblem(models.GameConfigMethodPower, 2, 5), "2⁵", big.NewRat(32, 1)},
+		{"negative base", powerProblem(models.GameConfigMethodPower, -3, 3), "(-3)³", big.NewRat(-27, 1)},
+		{"negative exponent", powerProblem(models.GameConfigMethodPower, 4, -2), "4⁻²", big.NewRat(1, 16)},
+		{"zero to the zero", powerProblem(models.GameConfigMethodPower, 0, 0), "0¹", big.NewRat(0, 1)},
+		{"square", powerProblem(models.GameConfigMethodSquare, 12, 2), "12²", big.NewRat(144, 1)},
+		{"square root", squareRootProblem(9), "√81", big.NewRat(9, 1)},
+		{"power of ten", powerOfTenProblem(7, 3), "7 × 10³", big.NewRat(7000, 1)},
+		{"small power of ten", powerOfTenProblem(7, -2), "7 × 10⁻²", big.NewRat(7, 100)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.problem.Text != test.text {
+				t.Errorf("expected %q, got %q", test.text, test.problem.Text)
+			}
+			if ratOf(test.problem.Answer).Cmp(test.answer) != 0 {
+				t.Errorf("expected %s, got %s", test.answer, test.problem.Answer)
+			}
+		})
+	}
+
+	if answer := powerOfTenProblem(7, -2).Answer; answer.Kind != models.ValueKindDecimal {
+		t.Errorf("expected a negative power of ten to be answered as a decimal, got %s", answer.Kind)
+	}
+}
+
+func TestGeneratePowerProblems(t *testing.T) {
+	config := models.GameConfig{
+		Methods: []models.GameConfigMethod{
+			models.GameConfigMethodPower,
+			models.GameConfigMethodSquare,
+			models.GameConfigMethodCube,
+			models.GameConfigMethodSquareRoot,
+		},
+		Range: models.GameConfigRange{Min: 1, Max: 12},
+		Powers: &models.GameConfigPowers{
+			Bases:     &models.GameConfigRange{Min: 2, Max: 6},
+			Exponents: &models.GameConfigRange{Min: 1, Max: 3},
+		},
+	}
+	for seed := int64(0); seed < 50; seed++ {
+		for _, problem := range GenerateGameProblems(config, seed) {
+			switch problem.Method {
+			case models.GameConfigMethodSquareRoot:
+				root := problem.Answer.Int()
+				if root*root != problem.Number1 || root < defaultSquareRoots.Min || root > defaultSquareRoots.Max {
+					t.Fatalf("seed %d: %s = %d is not a perfect square in the default roots", seed, problem.Text, root)
+				}
+				continue
+			case models.GameConfigMethodSquare:
+				if problem.Number2 != 2 {
+					t.Fatalf("seed %d: expected a square, got %s", seed, problem.Text)
+				}
+			case models.GameConfigMethodCube:
+				if problem.Number2 != 3 {
+					t.Fatalf("seed %d: expected a cube, got %s", seed, problem.Text)
+				}
+			default:
+				if problem.Number2 < 1 || problem.Number2 > 3 {
+					t.Fatalf("seed %d: exponent of %s is outside 1 to 3", seed, problem.Text)
+				}
+			}
+			if problem.Number1 < 2 || problem.Number1 > 6 {
+				t.Fatalf("seed %d: base of %s is outside 2 to 6", seed, problem.Text)
+			}
+			if ratOf(problem.Answer).Cmp(power(problem.Number1, problem.Number2)) != 0 {
+				t.Fatalf("seed %d: %s should be %s, got %s", seed, problem.Text, power(problem.Number1, problem.Number2), problem.Answer)
+			}
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package game
+
+import (
+	"time"
+
+	"github.com/FiveEightyEight/mwfapi/models"
+	"github.com/google/uuid"
+)
+
+// PerPlayer reports whether config gives each player their own problems
+// rather than one set shared by everyone.
+func PerPlayer(config models.GameConfig) bool {
+	switch config.Mode {
+	case models.GameModeAdaptive, models.GameModeIndividual, models.GameModeReview:
+		return true
+	}
+	return false
+}
+
+// StartProgress puts a player on their first problem. problems is the
+// player's own set in an individual or review game, and nil in an adaptive
+// one.
+func StartProgress(config models.GameConfig, seed int64, userID uuid.UUID, problems []models.GameProblem, now time.Time) models.PlayerProgress {
+	progress := models.PlayerProgress{
+		UserID:    userID,
+		Recent:    []models.ProblemResult{},
+		StartedAt: now,
+	}
+	if config.Mode != models.GameModeAdaptive {
+		progress.Problems = problems
+		progress.Difficulty = totalDifficulty(problems)
+		if len(problems) > 0 {
+			progress.Problem = &problems[0]
+		}
+		return progress
+	}
+
+	progress.Level = adaptiveSettings(config).StartLevel
+	problem := GenerateAdaptiveProblem(config, seed, userID, 0, progress.Level, nil)
+	progress.Problem = &problem
+	return progress
+}
+
+// AdvanceProgress records how a player did on their current problem and
+// gives them the next one, or none once they have answered the whole set.
+// In an adaptive game their level first moves if their recent results call
+// for it.
+func AdvanceProgress(config models.GameConfig, seed int64, progress *models.PlayerProgress, correct bool, now time.Time) {
+	settings := adaptiveSettings(config)
+	result := models.ProblemResult{Correct: correct, TimeMs: int(now.Sub(progress.StartedAt).Milliseconds())}
+	progress.Recent = append(progress.Recent, result)
+	if len(progress.Recent) > settings.Window {
+		progress.Recent = progress.Recent[len(progress.Recent)-settings.Window:]
+	}
+	progress.Answered++
+	progress.StartedAt = now
+
+	if config.Mode != models.GameModeAdaptive {
+		progress.Problem = nil
+		if progress.Answered < len(progress.Problems) {
+			progress.Problem = &progress.Problems[progress.Answered]
+		}
+		return
+	}
+	if progress.Problem != nil {
+		progress.Asked = append(progress.Asked, ReviewKey(*progress.Problem))
+	}
+
+	if level := NextLevel(config, progress.Level, progress.Recent); level != progress.Level {
+		// Judge the new level on answers given at it
+		progress.Level = level
+		progress.Recent = []models.ProblemResult{}
+	}
+	count := config.ProblemCount
+	if count == 0 {
+		count = DefaultProblemCount
+	}
+	if progress.Answered >= count {
+		progress.Problem = nil
+		return
+	}
+	problem := GenerateAdaptiveProblem(config, seed, progress.UserID, progress.Answered, progress.Level, progress.Asked)
+	progress.Problem = &problem
+}
@@ -0,0 +1,219 @@
+package game
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/FiveEightyEight/mwfapi/models"
+)
+
+// ReviewIntervals is how long a fact waits in each Leitner box before it is
+// due for review again. A fact answered right in the last box leaves the
+// queue.
+var ReviewIntervals = []time.Duration{
+	10 * time.Minute,
+	24 * time.Hour,
+	3 * 24 * time.Hour,
+	7 * 24 * time.Hour,
+	21 * 24 * time.Hour,
+}
+
+const (
+	// MaxReviewItems caps a user's review queue. Past it the facts furthest
+	// from being due are dropped.
+	MaxReviewItems = 200
+
+	// An answer is slow when it takes slowFactor times as long as the
+	// problem's difficulty suggests, and at least minSlowMs.
+	slowFactor = 2.0
+	minSlowMs  = 5000
+)
+
+// ReviewKey identifies the fact problem asks, whatever choices it was
+// offered with or however it was worded.
+func ReviewKey(problem models.GameProblem) string {
+	sum := sha256.Sum256([]byte(problemKey(reviewFact(problem))))
+	return hex.EncodeToString(sum[:8])
+}
+
+// reviewFact is problem without the parts that only vary how it was shown.
+func reviewFact(problem models.GameProblem) models.GameProblem {
+	problem.Choices = nil
+	problem.DisplayMs = 0
+	return problem
+}
+
+// NeedsReview reports whether an answer to problem should send it to the
+// review queue: it was wrong, or slow for a problem that difficult. A
+// timeMs of zero means the time is unknown.
+func NeedsReview(problem models.GameProblem, correct bool, timeMs int) bool {
+	if !correct {
+		return true
+	}
+	// Difficulty is about log₂ of one more than the seconds a problem takes
+	expectedMs := (math.Exp2(Difficulty(problem)) - 1) * 1000
+	return float64(timeMs) > math.Max(slowFactor*expectedMs, minSlowMs)
+}
+
+// RecordMiss puts problem back in box 1 of the queue after a wrong or slow
+// answer in a game graded as grading says. item is the fact's current entry,
+// or nil if it is not queued.
+func RecordMiss(item *models.ReviewItem, problem models.GameProblem, grading models.ReviewGrading, now time.Time) models.ReviewItem {
+	next := models.ReviewItem{Key: ReviewKey(problem), Problem: reviewFact(problem)}
+	if item != nil {
+		next = *item
+	}
+	next.Grading = grading
+	next.Box = 1
+	next.Misses++
+	next.DueAt = now.Add(ReviewIntervals[0])
+	next.UpdatedAt = now
+	return next
+}
+
+// RecordReview reschedules item after it was answered in a review. A right
+// answer moves it up a box, and reports it done once it passes the last one.
+// A wrong one sends it back to box 1.
+func RecordReview(item models.ReviewItem, correct bool, now time.Time) (models.ReviewItem, bool) {
+	if !correct {
+		return RecordMiss(&item, item.Problem, item.Grading, now), false
+	}
+	if item.Box >= len(ReviewIntervals) {
+		return item, true
+	}
+	item.Box++
+	item.DueAt = now.Add(ReviewIntervals[item.Box-1])
+	item.UpdatedAt = now
+	return item, false
+}
+
+// DueReviews returns up to limit of items due by now, longest overdue first.
+func DueReviews(items []models.ReviewItem, now time.Time, limit int) []models.ReviewItem {
+	due := []models.ReviewItem{}
+	for _, item := range items {
+		if !item.DueAt.After(now) {
+			due = append(due, item)
+		}
+	}
+	SortReviews(due)
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	return due
+}
+
+// OverflowReviews returns the keys of the items to drop to bring the queue
+// down to MaxReviewItems, those due last going first.
+func OverflowReviews(items []models.ReviewItem) []string {
+	if len(items) <= MaxReviewItems {
+		return nil
+	}
+	sorted := append([]models.ReviewItem(nil), items...)
+	SortReviews(sorted)
+	keys := []string{}
+	for _, item := range sorted[MaxReviewItems:] {
+		keys = append(keys, item.Key)
+	}
+	return keys
+}
+
+// SortReviews orders items by when they are due, breaking ties on the
+// lower box.
+func SortReviews(items []models.ReviewItem) {
+	sort.SliceStable(items, func(i, j int) bool {
+		if !items[i].DueAt.Equal(items[j].DueAt) {
+			return items[i].DueAt.Before(items[j].DueAt)
+		}
+		return items[i].Box < items[j].Box
+	})
+}
+
+// GradingOf picks out the settings of config that decide how answers are
+// graded.
+func GradingOf(config models.GameConfig) models.ReviewGrading {
+	var grading models.ReviewGrading
+	if config.Fractions != nil {
+		grading.RequireSimplestForm = config.Fractions.RequireSimplestForm
+	}
+	if config.Decimals != nil {
+		grading.Tolerance = config.Decimals.Tolerance
+	}
+	if config.Estimation != nil {
+		grading.EstimationBands = config.Estimation.Bands
+	}
+	if config.Targets != nil {
+		grading.TargetBands = config.Targets.Bands
+	}
+	return grading
+}
+
+// GradingConfig is config with its grading settings replaced by grading's,
+// to grade an answer in a review as it was graded in the game the fact was
+// missed in.
+func GradingConfig(config models.GameConfig, grading models.ReviewGrading) models.GameConfig {
+	fractions := models.GameConfigFractions{}
+	if config.Fractions != nil {
+		fractions = *config.Fractions
+	}
+	fractions.RequireSimplestForm = grading.RequireSimplestForm
+	config.Fractions = &fractions
+
+	decimals := models.GameConfigDecimals{}
+	if config.Decimals != nil {
+		decimals = *config.Decimals
+	}
+	decimals.Tolerance = grading.Tolerance
+	config.Decimals = &decimals
+
+	estimation := models.GameConfigEstimation{}
+	if config.Estimation != nil {
+		estimation = *config.Estimation
+	}
+	estimation.Bands = grading.EstimationBands
+	config.Estimation = &estimation
+
+	targets := models.GameConfigTargets{}
+	if config.Targets != nil {
+		targets = *config.Targets
+	}
+	targets.Bands = grading.TargetBands
+	config.Targets = &targets
+	return config
+}
+
+// ReviewProblems builds a review game's problem set from the due items, in
+// random order, offering choices if config asks for them and the problem
+// can have them. At most the config's problem count are used. Each problem
+// comes with the grading of the item it was built from.
+func ReviewProblems(config models.GameConfig, seed int64, items []models.ReviewItem) ([]models.GameProblem, []models.ReviewGrading) {
+	random := rand.New(rand.NewSource(seed))
+	count := config.ProblemCount
+	if count == 0 {
+		count = DefaultProblemCount
+	}
+	if len(items) > count {
+		items = items[:count]
+	}
+
+	problems := make([]models.GameProblem, len(items))
+	gradings := make([]models.ReviewGrading, len(items))
+	for i, item := range items {
+		problems[i], gradings[i] = item.Problem, item.Grading
+	}
+	random.Shuffle(len(problems), func(i, j int) {
+		problems[i], problems[j] = problems[j], problems[i]
+		gradings[i], gradings[j] = gradings[j], gradings[i]
+	})
+	if config.MultipleChoice {
+		for i := range problems {
+			if problems[i].Method != models.GameConfigMethodTarget {
+				addChoices(GradingConfig(config, gradings[i]), &problems[i], random)
+			}
+		}
+	}
+	return problems, gradings
+}
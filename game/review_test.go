@@ -0,0 +1,72 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/FiveEightyEight/mwfapi/models"
+)
+
+func TestReviewBoxes(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	problem := models.GameProblem{Number1: 7, Number2: 8, Method: models.GameConfigMethodMultiply, Answer: models.IntegerValue(56)}
+	grading := models.ReviewGrading{RequireSimplestForm: true}
+
+	item := RecordMiss(nil, problem, grading, now)
+	if item.Box != 1 || item.Misses != 1 || !item.DueAt.Equal(now.Add(ReviewIntervals[0])) {
+		t.Fatalf("expected a new item in box 1 due after %v, got box %d, %d misses, due %v", ReviewIntervals[0], item.Box, item.Misses, item.DueAt)
+	}
+	if item.Key != ReviewKey(problem) || !item.Grading.RequireSimplestForm {
+		t.Fatalf("expected the item to keep its key and grading, got %+v", item)
+	}
+
+	// Each right answer moves it up a box, waiting longer
+	for box := 2; box <= len(ReviewIntervals); box++ {
+		var done bool
+		item, done = RecordReview(item, true, now)
+		if done || item.Box != box || !item.DueAt.Equal(now.Add(ReviewIntervals[box-1])) {
+			t.Fatalf("expected box %d due after %v, got box %d due %v, done %v", box, ReviewIntervals[box-1], item.Box, item.DueAt, done)
+		}
+	}
+
+	// A miss sends it back to box 1, graded as before
+	missed, done := RecordReview(item, false, now)
+	if done || missed.Box != 1 || missed.Misses != 2 || !missed.Grading.RequireSimplestForm {
+		t.Fatalf("expected a miss to return to box 1 with its grading, got %+v, done %v", missed, done)
+	}
+
+	// A right answer in the last box takes it off the queue
+	if _, done := RecordReview(item, true, now); !done {
+		t.Fatal("expected a right answer in the last box to finish the item")
+	}
+
+	// Missed again in another game, it is graded as that game grades
+	again := RecordMiss(&missed, problem, models.ReviewGrading{}, now)
+	if again.Misses != 3 || again.Grading.RequireSimplestForm {
+		t.Fatalf("expected the latest game's grading, got %+v", again)
+	}
+}
+
+func TestReviewGrading(t *testing.T) {
+	tolerance := models.DecimalValue(1, 1)
+	missedIn := models.GameConfig{
+		Methods:  []models.GameConfigMethod{models.GameConfigMethodDecimal},
+		Range:    models.GameConfigRange{Min: 1, Max: 12},
+		Decimals: &models.GameConfigDecimals{Tolerance: &tolerance},
+	}
+	problem := models.GameProblem{Method: models.GameConfigMethodDecimal, Answer: models.DecimalValue(25, 1)}
+	item := RecordMiss(nil, problem, GradingOf(missedIn), time.Now())
+
+	review := models.GameConfig{Mode: models.GameModeReview, ProblemCount: 5}
+	problems, gradings := ReviewProblems(review, 1, []models.ReviewItem{item})
+	if len(problems) != 1 || len(gradings) != 1 {
+		t.Fatalf("expected one problem and its grading, got %d and %d", len(problems), len(gradings))
+	}
+	near := models.DecimalValue(26, 1)
+	if CheckAnswer(review, problems[0], near) {
+		t.Error("expected the review game's own config to require an exact answer")
+	}
+	if !CheckAnswer(GradingConfig(review, gradings[0]), problems[0], near) {
+		t.Error("expected the answer within the tolerance the fact was missed with to count")
+	}
+}
@@ -0,0 +1,123 @@
+package game
+
+import (
+	"math/rand"
+	"strings"
+
+	"github.com/FiveEightyEight/mwfapi/models"
+)
+
+const (
+	DefaultSequenceLength   = 5
+	DefaultSequenceMaxStep  = 10
+	DefaultSequenceMaxRatio = 3
+	MinSequenceLength       = 4
+	MaxSequenceLength       = 8
+	MaxSequenceStep         = 100
+	MaxSequenceRatio        = 5
+
+	maxSequenceStart = 10
+)
+
+var sequenceKinds = []models.SequenceKind{
+	models.SequenceKindArithmetic,
+	models.SequenceKindGeometric,
+	models.SequenceKindSquare,
+	models.SequenceKindFibonacci,
+}
+
+func sequenceSettings(config models.GameConfig) models.GameConfigSequences {
+	var settings models.GameConfigSequences
+	if config.Sequences != nil {
+		settings = *config.Sequences
+	}
+	if len(settings.Kinds) == 0 {
+		settings.Kinds = sequenceKinds
+	}
+	if settings.Length == 0 {
+		settings.Length = DefaultSequenceLength
+	}
+	if settings.MaxStep == 0 {
+		settings.MaxStep = DefaultSequenceMaxStep
+	}
+	if settings.MaxRatio == 0 {
+		settings.MaxRatio = DefaultSequenceMaxRatio
+	}
+	return settings
+}
+
+// generateSequenceProblem shows the terms of a sequence with one left out,
+// the next one unless the config allows any term to be missing. Arithmetic
+// sequences start within the config's range, the others start small so the
+// terms don't grow out of hand.
+func generateSequenceProblem(config models.GameConfig, random *rand.Rand) models.GameProblem {
+	settings := sequenceSettings(config)
+	kind := settings.Kinds[random.Intn(len(settings.Kinds))]
+	small := models.GameConfigRange{Min: 1, Max: maxSequenceStart}
+
+	terms := make([]int, settings.Length)
+	switch kind {
+	case models.SequenceKindArithmetic:
+		terms[0] = randomIn(config.Range, random)
+		step := random.Intn(settings.MaxStep) + 1
+		for i := 1; i < len(terms); i++ {
+			terms[i] = terms[i-1] + step
+		}
+	case models.SequenceKindGeometric:
+		terms[0] = randomIn(small, random)
+		ratio := random.Intn(settings.MaxRatio-1) + 2
+		for i := 1; i < len(terms); i++ {
+			terms[i] = terms[i-1] * ratio
+		}
+	case models.SequenceKindSquare:
+		root := randomIn(small, random)
+		for i := range terms {
+			terms[i] = (root + i) * (root + i)
+		}
+	case models.SequenceKindFibonacci:
+		terms[0], terms[1] = randomIn(small, random), randomIn(small, random)
+		for i := 2; i < len(terms); i++ {
+			terms[i] = terms[i-1] + terms[i-2]
+		}
+	}
+
+	// Arithmetic and geometric sequences count down as often as up
+	if (kind == models.SequenceKindArithmetic || kind == models.SequenceKindGeometric) && random.Intn(2) == 0 {
+		for i, j := 0, len(terms)-1; i < j; i, j = i+1, j-1 {
+			terms[i], terms[j] = terms[j], terms[i]
+		}
+	}
+
+	missing := len(terms) - 1
+	if settings.MissingTerm {
+		missing = random.Intn(len(terms))
+	}
+
+	shown := make([]models.Value, len(terms))
+	texts := make([]string, len(terms))
+	for i, term := range terms {
+		if i == missing {
+			texts[i] = "?"
+			continue
+		}
+		shown[i] = models.IntegerValue(term)
+		texts[i] = shown[i].String()
+	}
+
+	return models.GameProblem{
+		Method: models.GameConfigMethodSequence,
+		Answer: models.IntegerValue(terms[missing]),
+		Terms:  shown,
+		Text:   strings.Join(texts, ", "),
+	}
+}
+
+// missingTerm returns the index of the term a sequence problem asks for.
+func missingTerm(problem models.GameProblem) int {
+	for i, term := range problem.Terms {
+		if term.IsZero() {
+			return i
+		}
+	}
+	return len(problem.Terms)
+}
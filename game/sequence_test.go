@@ -0,0 +1,69 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/FiveEightyEight/mwfapi/models"
+)
+
+func TestGenerateSequenceProblem(t *testing.T) {
+	tests := []struct {
+		name   string
+		kind   models.SequenceKind
+		follow func(terms []int, i int) bool
+	}{
+		{"arithmetic", models.SequenceKindArithmetic, func(terms []int, i int) bool {
+			step := terms[1] - terms[0]
+			return step != 0 && abs(step) <= 4 && terms[i]-terms[i-1] == step
+		}},
+		{"geometric", models.SequenceKindGeometric, func(terms []int, i int) bool {
+			up, down := terms[i] == terms[i-1]*2 || terms[i] == terms[i-1]*3, terms[i-1] == terms[i]*2 || terms[i-1] == terms[i]*3
+			return up || down
+		}},
+		{"square", models.SequenceKindSquare, func(terms []int, i int) bool {
+			return terms[i]-terms[i-1] == terms[1]-terms[0]+2*(i-1)
+		}},
+		{"fibonacci", models.SequenceKindFibonacci, func(terms []int, i int) bool {
+			return i < 2 || terms[i] == terms[i-1]+terms[i-2]
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, missingTermAllowed := range []bool{false, true} {
+				config := models.GameConfig{
+					Methods: []models.GameConfigMethod{models.GameConfigMethodSequence},
+					Range:   models.GameConfigRange{Min: 1, Max: 20},
+					Sequences: &models.GameConfigSequences{
+						Kinds:       []models.SequenceKind{test.kind},
+						Length:      6,
+						MaxStep:     4,
+						MaxRatio:    3,
+						MissingTerm: missingTermAllowed,
+					},
+				}
+				for seed := int64(0); seed < 20; seed++ {
+					for _, problem := range GenerateGameProblems(config, seed) {
+						missing := missingTerm(problem)
+						if len(problem.Terms) != 6 || missing == len(problem.Terms) {
+							t.Fatalf("seed %d: expected 6 terms with one left out, got %s", seed, problem.Text)
+						}
+						if !missingTermAllowed && missing != 5 {
+							t.Fatalf("seed %d: expected the next term to be asked for, got %s", seed, problem.Text)
+						}
+						terms := make([]int, len(problem.Terms))
+						for i, term := range problem.Terms {
+							terms[i] = term.Int()
+						}
+						terms[missing] = ExpectedAnswer(problem).Int()
+						for i := 1; i < len(terms); i++ {
+							if !test.follow(terms, i) {
+								t.Fatalf("seed %d: %s answered %d is not %s", seed, problem.Text, terms[missing], test.kind)
+							}
+						}
+					}
+				}
+			}
+		})
+	}
+}
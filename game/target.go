@@ -0,0 +1,293 @@
+package game
+
+import (
+	"fmt"
+	"math/bits"
+	"math/rand"
+	"slices"
+	"strings"
+
+	"github.com/FiveEightyEight/mwfapi/models"
+)
+
+const (
+	DefaultTargetNumbers = 6
+	DefaultTargetLarge   = 1
+	DefaultMinTarget     = 100
+	DefaultMaxTarget     = 999
+	MinTargetNumbers     = 3
+	MaxTargetNumbers     = 6
+	MaxTarget            = 9999
+
+	targetDeals = 20
+)
+
+var largeNumbers = []int{25, 50, 75, 100}
+
+var defaultTargetBands = []models.GameConfigErrorBand{
+	{Within: 0, Points: 3},
+	{Within: 5, Points: 2},
+	{Within: 10, Points: 1},
+}
+
+func targetSettings(config models.GameConfig) models.GameConfigTargets {
+	var settings models.GameConfigTargets
+	if config.Targets != nil {
+		settings = *config.Targets
+	}
+	if settings.Numbers == 0 {
+		settings.Numbers = DefaultTargetNumbers
+	}
+	if settings.Large == nil {
+		large := DefaultTargetLarge
+		settings.Large = &large
+	}
+	if settings.MinTarget == 0 {
+		settings.MinTarget = DefaultMinTarget
+	}
+	if settings.MaxTarget == 0 {
+		settings.MaxTarget = DefaultMaxTarget
+	}
+	if len(settings.Bands) == 0 {
+		settings.Bands = defaultTargetBands
+	}
+	return settings
+}
+
+// generateTargetProblem deals the numbers and picks a target between the
+// config's bounds that the solver can reach with them, dealing again when
+// none can be reached, so every puzzle is solvable.
+func generateTargetProblem(config models.GameConfig, random *rand.Rand) models.GameProblem {
+	settings := targetSettings(config)
+
+	var numbers []int
+	var solution targetTerm
+	for deal := 0; deal < targetDeals; deal++ {
+		numbers = dealTargetNumbers(settings, random)
+		reached := reachTargets(numbers)
+
+		var inBounds []targetTerm
+		for _, term := range reached {
+			if term.value >= settings.MinTarget && term.value <= settings.MaxTarget {
+				inBounds = append(inBounds, term)
+			}
+		}
+		if len(inBounds) > 0 {
+			solution = inBounds[random.Intn(len(inBounds))]
+			break
+		}
+		// Validation keeps the bounds within reach of the best deal, so this
+		// is only kept if every deal falls short of them
+		solution = closestTerm(reached, settings.MinTarget)
+	}
+
+	operands := make([]models.Value, len(numbers))
+	texts := make([]string, len(numbers))
+	for i, n := range numbers {
+		operands[i] = models.IntegerValue(n)
+		texts[i] = operands[i].String()
+	}
+
+	return models.GameProblem{
+		Method:   models.GameConfigMethodTarget,
+		Operands: operands,
+		Target:   solution.value,
+		Answer:   models.IntegerValue(solution.value),
+		Solution: RenderExpression(solution.expr),
+		Text:     fmt.Sprintf("Reach %d using %s", solution.value, strings.Join(texts, ", ")),
+	}
+}
+
+// dealTargetNumbers deals the large numbers the settings ask for and fills
+// the rest with small ones.
+func dealTargetNumbers(settings models.GameConfigTargets, random *rand.Rand) []int {
+	numbers := make([]int, 0, settings.Numbers)
+	for _, i := range random.Perm(len(largeNumbers))[:*settings.Large] {
+		numbers = append(numbers, largeNumbers[i])
+	}
+	for len(numbers) < settings.Numbers {
+		numbers = append(numbers, random.Intn(10)+1)
+	}
+	return numbers
+}
+
+// largestTarget is the largest value any deal the settings allow can reach:
+// the product of the biggest numbers that can be dealt.
+func largestTarget(settings models.GameConfigTargets) int {
+	largest := 1
+	for i := 0; i < *settings.Large; i++ {
+		largest *= largeNumbers[len(largeNumbers)-1-i]
+	}
+	for i := *settings.Large; i < settings.Numbers; i++ {
+		largest *= 10
+	}
+	return largest
+}
+
+// TargetSolution is the closest a solver got to a target and how.
+type TargetSolution struct {
+	Value      int
+	Expression *models.Expression
+}
+
+type targetTerm struct {
+	value int
+	expr  *models.Expression
+}
+
+// SolveTarget finds an expression using each of numbers at most once that
+// comes to target, returning the closest one if target can't be reached.
+// Every step stays a positive whole number.
+func SolveTarget(numbers []int, target int) TargetSolution {
+	best := closestTerm(reachTargets(numbers), target)
+	return TargetSolution{Value: best.value, Expression: best.expr}
+}
+
+// closestTerm returns the first of terms whose value is closest to target.
+func closestTerm(terms []targetTerm, target int) targetTerm {
+	best := terms[0]
+	for _, term := range terms[1:] {
+		if abs(term.value-target) < abs(best.value-target) {
+			best = term
+		}
+	}
+	return best
+}
+
+// reachTargets lists each value some of numbers can be combined into, with
+// one expression that reaches it. It works up from single numbers, combining
+// what every subset of numbers reaches with what the numbers left over do,
+// so each subset is solved once however many ways it can be split. Smaller
+// subsets come first, so a value keeps the expression using the fewest
+// numbers. The order is fixed by numbers alone.
+func reachTargets(numbers []int) []targetTerm {
+	subsets := make([][]targetTerm, 1<<len(numbers))
+	masks := make([]int, 0, len(subsets)-1)
+	for mask := 1; mask < len(subsets); mask++ {
+		masks = append(masks, mask)
+	}
+	slices.SortStableFunc(masks, func(a, b int) int {
+		return bits.OnesCount(uint(a)) - bits.OnesCount(uint(b))
+	})
+
+	var reached []targetTerm
+	found := map[int]bool{}
+	for _, mask := range masks {
+		var terms []targetTerm
+		seen := map[int]bool{}
+		add := func(value int, expr *models.Expression) {
+			if !seen[value] {
+				seen[value] = true
+				terms = append(terms, targetTerm{value, expr})
+			}
+		}
+
+		if bits.OnesCount(uint(mask)) == 1 {
+			n := numbers[bits.TrailingZeros(uint(mask))]
+			add(n, leafExpression(n))
+		}
+		// Each split once, with the lowest number on the left
+		low := mask & -mask
+		for left := (mask - 1) & mask; left > 0; left = (left - 1) & mask {
+			if left&low == 0 {
+				continue
+			}
+			for _, a := range subsets[left] {
+				for _, b := range subsets[mask^left] {
+					combineTargetTerms(a, b, add)
+				}
+			}
+		}
+
+		subsets[mask] = terms
+		for _, term := range terms {
+			if !found[term.value] {
+				found[term.value] = true
+				reached = append(reached, term)
+			}
+		}
+	}
+	return reached
+}
+
+// combineTargetTerms passes add each way a and b combine into a positive
+// whole number, leaving out steps that give back one of them unchanged.
+func combineTargetTerms(a, b targetTerm, add func(value int, expr *models.Expression)) {
+	if a.value < b.value {
+		a, b = b, a
+	}
+	combine := func(operator models.GameConfigMethod, value int) {
+		add(value, &models.Expression{Operator: operator, Left: a.expr, Right: b.expr})
+	}
+
+	combine(models.GameConfigMethodAdd, a.value+b.value)
+	if a.value != b.value && a.value-b.value != b.value {
+		combine(models.GameConfigMethodSubtract, a.value-b.value)
+	}
+	if b.value != 1 {
+		combine(models.GameConfigMethodMultiply, a.value*b.value)
+		if a.value%b.value == 0 && a.value/b.value != b.value {
+			combine(models.GameConfigMethodDivide, a.value/b.value)
+		}
+	}
+}
+
+// TargetAnswer works out what an expression submitted for a target puzzle
+// comes to. It must use only the puzzle's numbers, each no more often than
+// it was dealt, and every division must come out exact. Otherwise it returns
+// a *Rejection.
+func TargetAnswer(problem models.GameProblem, text string) (models.Value, error) {
+	expr, err := ParseExpression(text)
+	if err != nil {
+		return models.Value{}, reject(RejectionInvalidExpression, "expression", "%v", err)
+	}
+
+	available := map[int]int{}
+	for _, operand := range problem.Operands {
+		available[operand.Int()]++
+	}
+	for _, n := range expressionLeaves(expr) {
+		if available[n] == 0 {
+			return models.Value{}, reject(RejectionUnavailableNumber, "expression", "%d is not one of the numbers left to use", n)
+		}
+		available[n]--
+	}
+
+	value, ok := EvaluateExpression(expr)
+	if !ok {
+		return models.Value{}, reject(RejectionInexactDivision, "expression", "every division must come out exact")
+	}
+	return models.IntegerValue(value), nil
+}
+
+// BotExpression returns the expression a bot with the given profile submits
+// for a target puzzle: the solution, or with a miss one aimed a little off.
+func BotExpression(problem models.GameProblem, profile models.BotProfile, random *rand.Rand) string {
+	if random.Float64() < profile.Accuracy {
+		return problem.Solution
+	}
+	numbers := make([]int, len(problem.Operands))
+	for i, operand := range problem.Operands {
+		numbers[i] = operand.Int()
+	}
+	miss := random.Intn(10) + 1
+	if random.Intn(2) == 0 {
+		miss = -miss
+	}
+	return RenderExpression(SolveTarget(numbers, problem.Target+miss).Expression)
+}
+
+// scoreTarget awards the points of the best band the reached value falls in.
+func scoreTarget(config models.GameConfig, submitted, expected models.Value) int {
+	if submitted.Kind != models.ValueKindInteger {
+		return 0
+	}
+	distance := abs(submitted.Int() - expected.Int())
+	points := 0
+	for _, band := range targetSettings(config).Bands {
+		if distance <= band.Within && band.Points > points {
+			points = band.Points
+		}
+	}
+	return points
+}
@@ -0,0 +1,143 @@
+package game
+
+import (
+	"errors"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/FiveEightyEight/mwfapi/models"
+)
+
+// usesDealt reports whether expr uses only numbers, each no more often than
+// it appears there.
+func usesDealt(expr *models.Expression, numbers []int) bool {
+	left := slices.Clone(numbers)
+	for _, n := range expressionLeaves(expr) {
+		i := slices.Index(left, n)
+		if i == -1 {
+			return false
+		}
+		left = slices.Delete(left, i, i+1)
+	}
+	return true
+}
+
+func TestSolveTarget(t *testing.T) {
+	tests := []struct {
+		numbers []int
+		target  int
+		value   int
+	}{
+		{[]int{100, 75, 50, 25, 6, 3}, 952, 952},
+		{[]int{2, 3}, 6, 6},
+		{[]int{1, 1, 1}, 100, 3},
+		{[]int{10, 10, 10}, 999, 1000},
+	}
+	for _, test := range tests {
+		solution := SolveTarget(test.numbers, test.target)
+		value, ok := EvaluateExpression(solution.Expression)
+		if solution.Value != test.value || !ok || value != test.value {
+			t.Errorf("%v to %d: expected %d, got %s = %d", test.numbers, test.target, test.value, RenderExpression(solution.Expression), solution.Value)
+		}
+		if !usesDealt(solution.Expression, test.numbers) {
+			t.Errorf("%v to %d: %s uses numbers that weren't dealt", test.numbers, test.target, RenderExpression(solution.Expression))
+		}
+	}
+}
+
+func TestGenerateTargetProblem(t *testing.T) {
+	large := 0
+	config := models.GameConfig{
+		Methods:      []models.GameConfigMethod{models.GameConfigMethodTarget},
+		Range:        models.GameConfigRange{Min: 1, Max: 10},
+		ProblemCount: 50,
+		Targets:      &models.GameConfigTargets{Large: &large, MinTarget: 9000, MaxTarget: 9999},
+	}
+	if err := ValidateGameConfig(config); err != nil {
+		t.Fatalf("config should be valid: %v", err)
+	}
+
+	for _, problem := range GenerateGameProblems(config, 1) {
+		if problem.Target < 9000 || problem.Target > 9999 {
+			t.Fatalf("%s: target is outside 9000 to 9999", problem.Text)
+		}
+		numbers := make([]int, len(problem.Operands))
+		for i, operand := range problem.Operands {
+			numbers[i] = operand.Int()
+			if numbers[i] < 1 || numbers[i] > 10 {
+				t.Fatalf("%s: dealt %d without large numbers", problem.Text, numbers[i])
+			}
+		}
+		value, err := TargetAnswer(problem, problem.Solution)
+		if err != nil || value.Int() != problem.Target {
+			t.Fatalf("%s: solution %s comes to %s, %v", problem.Text, problem.Solution, value, err)
+		}
+	}
+
+	config.Targets = &models.GameConfigTargets{Numbers: 3, Large: &large, MinTarget: 2000, MaxTarget: 3000}
+	fields := errorFields(t, ValidateGameConfig(config))
+	if !slices.Contains(fields, "targets.min_target") {
+		t.Errorf("expected targets beyond three small numbers to be rejected, got %v", fields)
+	}
+}
+
+func TestTargetAnswer(t *testing.T) {
+	problem := models.GameProblem{
+		Method:   models.GameConfigMethodTarget,
+		Operands: []models.Value{models.IntegerValue(50), models.IntegerValue(7), models.IntegerValue(7), models.IntegerValue(3)},
+		Target:   357,
+		Answer:   models.IntegerValue(357),
+	}
+	config := models.GameConfig{Methods: []models.GameConfigMethod{models.GameConfigMethodTarget}}
+
+	tests := []struct {
+		name       string
+		expression string
+		points     int
+		reason     RejectionReason
+	}{
+		{"number used twice", "(50 + 7 × 3) ÷ 7 × 50", 0, RejectionUnavailableNumber},
+		{"number not dealt", "(50 + 1) × 7", 0, RejectionUnavailableNumber},
+		{"hit", "50 × 7 + 7", 3, ""},
+		{"within 5", "50 × 7 + 3", 2, ""},
+		{"within 10", "50 × 7", 1, ""},
+		{"too far", "50 × 3", 0, RejectionIncorrect},
+		{"inexact division", "50 ÷ 3 × 7", 0, RejectionInexactDivision},
+		{"not an expression", "50 ×", 0, RejectionInvalidExpression},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			points, err := ValidateSubmission(config, problem, models.Submission{Expression: test.expression})
+			if points != test.points {
+				t.Errorf("expected %d points, got %d", test.points, points)
+			}
+			var rejection *Rejection
+			if test.reason != "" && (!errors.As(err, &rejection) || rejection.Reason != test.reason) {
+				t.Errorf("expected a %s rejection, got %v", test.reason, err)
+			}
+		})
+	}
+}
+
+func TestBotExpression(t *testing.T) {
+	numbers := []int{75, 8, 6, 4, 3, 1}
+	solution := SolveTarget(numbers, 642)
+	problem := models.GameProblem{Method: models.GameConfigMethodTarget, Target: 642, Solution: RenderExpression(solution.Expression)}
+	for _, n := range numbers {
+		problem.Operands = append(problem.Operands, models.IntegerValue(n))
+	}
+
+	random := rand.New(rand.NewSource(1))
+	perfect, hopeless := BotSkills["hard"], BotSkills["easy"]
+	perfect.Accuracy, hopeless.Accuracy = 1, 0
+	if expression := BotExpression(problem, perfect, random); expression != problem.Solution {
+		t.Errorf("expected a perfect bot to give the solution, got %s", expression)
+	}
+	for i := 0; i < 10; i++ {
+		value, err := TargetAnswer(problem, BotExpression(problem, hopeless, random))
+		if err != nil || value.Int() == 642 || abs(value.Int()-642) > 10 {
+			t.Fatalf("expected a near miss, got %s, %v", value, err)
+		}
+	}
+}
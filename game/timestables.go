@@ -0,0 +1,76 @@
+package game
+
+import (
+	"math/rand"
+
+	"github.com/FiveEightyEight/mwfapi/models"
+)
+
+const (
+	DefaultTimesTableRepeats = 1
+	MaxTimesTableRepeats     = 5
+)
+
+var defaultMultipliers = models.GameConfigRange{Min: 1, Max: 10}
+
+func timesTableSettings(config models.GameConfig) models.GameConfigTimesTables {
+	settings := *config.TimesTables
+	multipliers := rangeOr(settings.Multipliers, defaultMultipliers)
+	settings.Multipliers = &multipliers
+	if settings.Repeats == 0 {
+		settings.Repeats = DefaultTimesTableRepeats
+	}
+	return settings
+}
+
+// timesTableFacts lists every fact of the drill once: each table times each
+// multiplier for multiply, and the product divided by the table for divide.
+// Facts the config excludes as trivial are left out.
+func timesTableFacts(config models.GameConfig) []models.GameProblem {
+	settings := timesTableSettings(config)
+	var facts []models.GameProblem
+	for _, table := range settings.Tables {
+		for m := settings.Multipliers.Min; m <= settings.Multipliers.Max; m++ {
+			for _, method := range config.Methods {
+				fact := models.GameProblem{Method: method}
+				switch method {
+				case models.GameConfigMethodMultiply:
+					fact.Number1, fact.Number2, fact.Answer = table, m, models.IntegerValue(table*m)
+				case models.GameConfigMethodDivide:
+					fact.Number1, fact.Number2, fact.Answer = table*m, table, models.IntegerValue(m)
+				}
+				if !isTrivial(config.Exclude, method, fact.Number1, fact.Number2) {
+					facts = append(facts, fact)
+				}
+			}
+		}
+	}
+	return facts
+}
+
+// generateTimesTableProblems deals out every fact Repeats times, shuffled.
+// Which side of a product the table goes on is picked at random.
+func generateTimesTableProblems(config models.GameConfig, random *rand.Rand) []models.GameProblem {
+	facts := timesTableFacts(config)
+	var problems []models.GameProblem
+	for i := 0; i < timesTableSettings(config).Repeats; i++ {
+		problems = append(problems, facts...)
+	}
+	random.Shuffle(len(problems), func(i, j int) {
+		problems[i], problems[j] = problems[j], problems[i]
+	})
+
+	for i := range problems {
+		problem := &problems[i]
+		if problem.Method == models.GameConfigMethodMultiply && random.Intn(2) == 0 {
+			problem.Number1, problem.Number2 = problem.Number2, problem.Number1
+		}
+		if config.MissingOperand {
+			problem.Blank = pickBlank(*problem, random)
+		}
+		if config.WordProblems != nil {
+			problem.Text = renderWordProblem(config, *problem, random)
+		}
+	}
+	return problems
+}
@@ -0,0 +1,59 @@
+package game
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/FiveEightyEight/mwfapi/models"
+)
+
+func TestGenerateTimesTableProblems(t *testing.T) {
+	config := models.GameConfig{
+		Methods: []models.GameConfigMethod{models.GameConfigMethodMultiply, models.GameConfigMethodDivide},
+		Range:   models.GameConfigRange{Min: 1, Max: 12},
+		TimesTables: &models.GameConfigTimesTables{
+			Tables:      []int{7, 8},
+			Multipliers: &models.GameConfigRange{Min: 1, Max: 12},
+			Repeats:     2,
+		},
+		Exclude: []models.GameConfigExclusion{models.GameConfigExcludeOne},
+	}
+
+	problems := GenerateGameProblems(config, 1)
+	// Each table times 2 to 12, and each product divided by the table,
+	// where only dividing by 1 counts as trivial, twice over
+	if len(problems) != 2*(11+12)*2 {
+		t.Fatalf("expected 92 problems, got %d", len(problems))
+	}
+
+	facts := map[string]int{}
+	for _, problem := range problems {
+		table, multiplier := problem.Number2, problem.Answer.Int()
+		if problem.Method == models.GameConfigMethodMultiply {
+			table, multiplier = problem.Number1, problem.Number2
+			if table != 7 && table != 8 {
+				table, multiplier = multiplier, table
+			}
+			if problem.Answer.Int() != table*multiplier {
+				t.Fatalf("%s: wrong product", problemKey(problem))
+			}
+		} else if problem.Number1 != table*multiplier {
+			t.Fatalf("%s: wrong quotient", problemKey(problem))
+		}
+		if table != 7 && table != 8 {
+			t.Fatalf("%s: not from the 7 or 8 times table", problemKey(problem))
+		}
+		if multiplier < 1 || multiplier > 12 || multiplier == 1 && problem.Method == models.GameConfigMethodMultiply {
+			t.Fatalf("%s: multiplier outside the drill", problemKey(problem))
+		}
+		facts[fmt.Sprintf("%s %d×%d", problem.Method, table, multiplier)]++
+	}
+	for fact, count := range facts {
+		if count != 2 {
+			t.Errorf("expected %s twice, got %d", fact, count)
+		}
+	}
+	if len(facts) != 2*(11+12) {
+		t.Errorf("expected 46 different facts, got %d", len(facts))
+	}
+}
@@ -0,0 +1,473 @@
+package game
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/FiveEightyEight/mwfapi/models"
+)
+
+const (
+	DefaultProblemCount = 10
+	MaxProblemCount     = 50
+	MaxOperand          = 10000
+	MaxMultiplyOperand  = 1000
+)
+
+// FieldError describes one invalid field of a GameConfig.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationErrors collects every FieldError found in a GameConfig.
+type ValidationErrors []FieldError
+
+func (ve ValidationErrors) Error() string {
+	messages := make([]string, len(ve))
+	for i, fe := range ve {
+		messages[i] = fmt.Sprintf("%s: %s", fe.Field, fe.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (ve *ValidationErrors) add(field, format string, args ...interface{}) {
+	*ve = append(*ve, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// ValidateGameConfig checks that config can be used to generate problems.
+// It returns ValidationErrors listing every invalid field, or nil.
+func ValidateGameConfig(config models.GameConfig) error {
+	var errs ValidationErrors
+
+	// A review game's problems come from each player's review queue
+	if len(config.Methods) == 0 && config.Mode != models.GameModeReview {
+		errs.add("methods", "at least one method is required")
+	}
+	seen := map[models.GameConfigMethod]bool{}
+	for i, method := range config.Methods {
+		field := fmt.Sprintf("methods[%d]", i)
+		switch method {
+		case models.GameConfigMethodAdd, models.GameConfigMethodSubtract, models.GameConfigMethodMultiply, models.GameConfigMethodDivide,
+			models.GameConfigMethodExpression, models.GameConfigMethodFraction,
+			models.GameConfigMethodDecimal, models.GameConfigMethodPercentOf, models.GameConfigMethodPercentRatio,
+			models.GameConfigMethodPower, models.GameConfigMethodSquare, models.GameConfigMethodCube,
+			models.GameConfigMethodSquareRoot, models.GameConfigMethodPowerOfTen,
+			models.GameConfigMethodGCD, models.GameConfigMethodLCM, models.GameConfigMethodIsPrime, models.GameConfigMethodPrimeFactors,
+			models.GameConfigMethodFactorPair, models.GameConfigMethodSequence, models.GameConfigMethodCompare, models.GameConfigMethodEstimate,
+			models.GameConfigMethodTarget:
+		default:
+			errs.add(field, "unknown method %q", method)
+			continue
+		}
+		if seen[method] {
+			errs.add(field, "duplicate method %q", method)
+		}
+		seen[method] = true
+	}
+
+	if config.ProblemCount < 0 || config.ProblemCount > MaxProblemCount {
+		errs.add("problem_count", "must be between 0 and %d (0 for default)", MaxProblemCount)
+	}
+
+	validateRange(&errs, "range", config.Range, MaxOperand)
+	for _, method := range config.Methods {
+		if r, ok := config.MethodRanges[method]; ok {
+			validateRange(&errs, fmt.Sprintf("method_ranges.%s", method), r, MaxOperand)
+		}
+	}
+	for method := range config.MethodRanges {
+		if !seen[method] {
+			errs.add(fmt.Sprintf("method_ranges.%s", method), "%q is not one of the methods", method)
+		}
+	}
+	// rangeOf is the range problems of method are drawn from
+	rangeOf := func(method models.GameConfigMethod) models.GameConfigRange {
+		return methodConfig(config, method).Range
+	}
+	switch config.Mode {
+	case "", models.GameModeFixed, models.GameModeIndividual:
+		if config.Adaptive != nil {
+			errs.add("adaptive", "only applies in adaptive mode")
+		}
+		if config.Mode == models.GameModeIndividual && config.TimesTables != nil {
+			errs.add("times_tables", "cannot be combined with individual mode")
+		}
+	case models.GameModeAdaptive:
+		validateAdaptiveConfig(&errs, config)
+	case models.GameModeReview:
+		if config.Adaptive != nil {
+			errs.add("adaptive", "only applies in adaptive mode")
+		}
+		if config.TimesTables != nil {
+			errs.add("times_tables", "cannot be combined with review mode")
+		}
+	default:
+		errs.add("mode", "unknown mode %q", config.Mode)
+	}
+	if config.TimesTables != nil {
+		validateTimesTableConfig(&errs, config)
+	} else if config.Mode != models.GameModeReview {
+		validateDistinctProblems(&errs, config)
+	}
+
+	if seen[models.GameConfigMethodCompare] {
+		validateComparisonConfig(&errs, methodConfig(config, models.GameConfigMethodCompare))
+	}
+	if seen[models.GameConfigMethodEstimate] {
+		validateEstimationConfig(&errs, methodConfig(config, models.GameConfigMethodEstimate))
+	}
+	exactDivision := config.MissingOperand || config.WordProblems != nil
+	for _, method := range []models.GameConfigMethod{models.GameConfigMethodMultiply, models.GameConfigMethodDivide} {
+		if !seen[method] || (method == models.GameConfigMethodDivide && !exactDivision) {
+			continue
+		}
+		if r := rangeOf(method); abs(r.Min) > MaxMultiplyOperand || abs(r.Max) > MaxMultiplyOperand {
+			errs.add("range", "must stay within -%d and %d when multiplying, or dividing with missing operands or word problems", MaxMultiplyOperand, MaxMultiplyOperand)
+		}
+	}
+	if r := rangeOf(models.GameConfigMethodDivide); seen[models.GameConfigMethodDivide] && config.TimesTables == nil && r.Min == 0 && r.Max == 0 {
+		errs.add("range", "must include a non-zero divisor when dividing")
+	}
+	for i, exclusion := range config.Exclude {
+		switch exclusion {
+		case models.GameConfigExcludeZero, models.GameConfigExcludeOne, models.GameConfigExcludeSame:
+		default:
+			errs.add(fmt.Sprintf("exclude[%d]", i), "unknown exclusion %q", exclusion)
+		}
+	}
+	if config.NegativeResults && config.WordProblems != nil {
+		errs.add("negative_results", "cannot be combined with word problems")
+	}
+
+	if seen[models.GameConfigMethodExpression] {
+		validateExpressionConfig(&errs, methodConfig(config, models.GameConfigMethodExpression))
+	}
+	if config.WordProblems != nil {
+		validateWordProblemConfig(&errs, config)
+	}
+	if config.Targets != nil {
+		validateTargetConfig(&errs, *config.Targets)
+	}
+	if seen[models.GameConfigMethodTarget] && config.MultipleChoice {
+		errs.add("multiple_choice", "cannot be combined with target puzzles")
+	}
+	if config.Fractions != nil {
+		validateFractionConfig(&errs, *config.Fractions)
+	}
+	if config.Decimals != nil {
+		validateDecimalConfig(&errs, *config.Decimals)
+	}
+	validatePowerConfig(&errs, config, seen)
+	for _, method := range []models.GameConfigMethod{models.GameConfigMethodGCD, models.GameConfigMethodLCM, models.GameConfigMethodIsPrime, models.GameConfigMethodPrimeFactors} {
+		if seen[method] && rangeOf(method).Max < 2 {
+			errs.add("range.max", "must be at least 2 for number theory problems")
+			break
+		}
+	}
+	if seen[models.GameConfigMethodFactorPair] && rangeOf(models.GameConfigMethodFactorPair).Max < 4 {
+		errs.add("range.max", "must be at least 4 for factor pair problems")
+	}
+	if config.Sequences != nil {
+		validateSequenceConfig(&errs, *config.Sequences)
+	}
+	if seen[models.GameConfigMethodLCM] && abs(rangeOf(models.GameConfigMethodLCM).Max) > MaxMultiplyOperand {
+		errs.add("range.max", "must not be greater than %d for LCM problems", MaxMultiplyOperand)
+	}
+	if seen[models.GameConfigMethodPercentOf] && rangeOf(models.GameConfigMethodPercentOf).Max < 1 ||
+		seen[models.GameConfigMethodPercentRatio] && rangeOf(models.GameConfigMethodPercentRatio).Max < 1 {
+		errs.add("range.max", "must be at least 1 for percentage problems")
+	}
+	if r := rangeOf(models.GameConfigMethodDecimal); seen[models.GameConfigMethodDecimal] && (abs(r.Min) > MaxMultiplyOperand || abs(r.Max) > MaxMultiplyOperand) {
+		errs.add("range", "must stay within -%d and %d for decimal problems", MaxMultiplyOperand, MaxMultiplyOperand)
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// validateDistinctProblems checks that the config has enough different
+// problems to fill the set without repeats. Methods that draw on too many
+// values to count always have enough.
+func validateDistinctProblems(errs *ValidationErrors, config models.GameConfig) {
+	count := config.ProblemCount
+	if count == 0 {
+		count = DefaultProblemCount
+	}
+	if possible, ok := DistinctProblemCount(config); ok && possible < count {
+		errs.add("range", "allows only %d different problems, fewer than the %d needed", possible, count)
+	}
+}
+
+func validateAdaptiveConfig(errs *ValidationErrors, config models.GameConfig) {
+	settings := adaptiveSettings(config)
+	if settings.MinLevel < MinLevel || settings.MaxLevel > MaxLevel || settings.MinLevel > settings.MaxLevel {
+		errs.add("adaptive", "min_level and max_level must be in order between %d and %d (0 for default)", MinLevel, MaxLevel)
+	}
+	if settings.StartLevel < settings.MinLevel || settings.StartLevel > settings.MaxLevel {
+		errs.add("adaptive.start_level", "must be between min_level and max_level")
+	}
+	if settings.Window < 1 || settings.Window > MaxAdaptiveWindow {
+		errs.add("adaptive.window", "must be between 0 and %d (0 for default)", MaxAdaptiveWindow)
+	}
+	if settings.TargetMs < 1 || settings.TargetMs > MaxTargetMs {
+		errs.add("adaptive.target_ms", "must be between 0 and %d (0 for default)", MaxTargetMs)
+	}
+	if config.TimesTables != nil {
+		errs.add("times_tables", "cannot be combined with adaptive mode")
+	}
+}
+
+func validateTimesTableConfig(errs *ValidationErrors, config models.GameConfig) {
+	settings := timesTableSettings(config)
+	for i, method := range config.Methods {
+		if method != models.GameConfigMethodMultiply && method != models.GameConfigMethodDivide {
+			errs.add(fmt.Sprintf("methods[%d]", i), "times tables only drill multiply and divide")
+		}
+	}
+	if len(settings.Tables) == 0 {
+		errs.add("times_tables.tables", "at least one table is required")
+	}
+	for i, table := range settings.Tables {
+		if table < 1 || table > MaxMultiplyOperand {
+			errs.add(fmt.Sprintf("times_tables.tables[%d]", i), "must be between 1 and %d", MaxMultiplyOperand)
+		}
+	}
+	validateRange(errs, "times_tables.multipliers", *settings.Multipliers, MaxMultiplyOperand)
+	if settings.Repeats < 1 || settings.Repeats > MaxTimesTableRepeats {
+		errs.add("times_tables.repeats", "must be between 0 and %d (0 for default)", MaxTimesTableRepeats)
+	}
+	if config.ProblemCount != 0 {
+		errs.add("problem_count", "cannot be set with times tables, every fact is drilled")
+	}
+	if len(*errs) > 0 {
+		return
+	}
+
+	if count := len(timesTableFacts(config)) * settings.Repeats; count < 1 || count > MaxProblemCount {
+		errs.add("times_tables", "makes %d problems, must make between 1 and %d", count, MaxProblemCount)
+	}
+}
+
+func validateExpressionConfig(errs *ValidationErrors, config models.GameConfig) {
+	if config.Range.Min < 0 {
+		errs.add("range.min", "must not be negative for expression problems")
+	}
+	if config.Expression == nil {
+		return
+	}
+
+	settings := expressionSettings(config)
+	for i, operation := range config.Expression.Operations {
+		if precedence(operation) == leafPrecedence {
+			errs.add(fmt.Sprintf("expression.operations[%d]", i), "unknown operation %q", operation)
+		}
+	}
+	if settings.Operators < 1 || settings.Operators > MaxExpressionOperators {
+		errs.add("expression.operators", "must be between 0 and %d (0 for default)", MaxExpressionOperators)
+	}
+	if settings.Depth < 0 || settings.Depth > MaxExpressionDepth {
+		errs.add("expression.depth", "must be between 0 and %d", MaxExpressionDepth)
+	}
+	if settings.MaxResult < 1 || settings.MaxResult > MaxOperand {
+		errs.add("expression.max_result", "must be between 0 and %d (0 for default)", MaxOperand)
+	}
+	if config.Range.Max > settings.MaxResult {
+		errs.add("range.max", "must not be greater than expression.max_result")
+	}
+}
+
+func validateRange(errs *ValidationErrors, field string, r models.GameConfigRange, limit int) {
+	if r.Min > r.Max {
+		errs.add(field, "min must not be greater than max")
+	}
+	if abs(r.Min) > limit {
+		errs.add(field+".min", "must be between -%d and %d", limit, limit)
+	}
+	if abs(r.Max) > limit {
+		errs.add(field+".max", "must be between -%d and %d", limit, limit)
+	}
+}
+
+func validateFractionConfig(errs *ValidationErrors, fractions models.GameConfigFractions) {
+	for i, operation := range fractions.Operations {
+		if precedence(operation) == leafPrecedence {
+			errs.add(fmt.Sprintf("fractions.operations[%d]", i), "unknown operation %q", operation)
+		}
+	}
+	if fractions.MaxDenominator != 0 && (fractions.MaxDenominator < 2 || fractions.MaxDenominator > MaxFractionDenominator) {
+		errs.add("fractions.max_denominator", "must be 0 for default or between 2 and %d", MaxFractionDenominator)
+	}
+}
+
+func validateDecimalConfig(errs *ValidationErrors, decimals models.GameConfigDecimals) {
+	for i, operation := range decimals.Operations {
+		if precedence(operation) == leafPrecedence {
+			errs.add(fmt.Sprintf("decimals.operations[%d]", i), "unknown operation %q", operation)
+		}
+	}
+	if decimals.Places < 0 || decimals.Places > MaxDecimalPlaces {
+		errs.add("decimals.places", "must be between 0 and %d (0 for default)", MaxDecimalPlaces)
+	}
+	if tolerance := decimals.Tolerance; tolerance != nil && (!tolerance.IsNumber() || tolerance.Kind == models.ValueKindFraction || tolerance.Numerator < 0) {
+		errs.add("decimals.tolerance", "must be a non-negative integer or decimal")
+	}
+}
+
+// validatePowerConfig checks the power ranges against every method that uses
+// them, including the defaults they are combined with, so no answer can grow
+// past MaxPowerResult.
+func validatePowerConfig(errs *ValidationErrors, config models.GameConfig, seen map[models.GameConfigMethod]bool) {
+	settings := powerSettings(config)
+	if settings.Bases != nil {
+		validateRange(errs, "powers.bases", *settings.Bases, MaxPowerBase)
+	}
+	if settings.Roots != nil {
+		validateRange(errs, "powers.roots", *settings.Roots, MaxPowerBase)
+		if settings.Roots.Min < 0 {
+			errs.add("powers.roots.min", "must not be negative")
+		}
+	}
+	if settings.Exponents != nil {
+		validateRange(errs, "powers.exponents", *settings.Exponents, MaxExponent)
+		if settings.Exponents.Min < 0 {
+			errs.add("powers.exponents.min", "must not be negative, use negative_exponents instead")
+		}
+	}
+	if len(*errs) > 0 {
+		return
+	}
+
+	largest := func(r models.GameConfigRange) int {
+		return max(abs(r.Min), abs(r.Max))
+	}
+	tooLarge := func(base, exponent int) bool {
+		return power(base, exponent).Cmp(big.NewRat(MaxPowerResult, 1)) > 0
+	}
+	if seen[models.GameConfigMethodPower] && tooLarge(largest(rangeOr(settings.Bases, defaultPowerBases)), rangeOr(settings.Exponents, defaultPowerExponents).Max) {
+		errs.add("powers", "bases and exponents must keep powers within %d", MaxPowerResult)
+	}
+	if seen[models.GameConfigMethodSquare] && tooLarge(largest(rangeOr(settings.Bases, defaultSquareBases)), 2) {
+		errs.add("powers.bases", "must keep squares within %d", MaxPowerResult)
+	}
+	if seen[models.GameConfigMethodCube] && tooLarge(largest(rangeOr(settings.Bases, defaultCubeBases)), 3) {
+		errs.add("powers.bases", "must keep cubes within %d", MaxPowerResult)
+	}
+	if seen[models.GameConfigMethodPowerOfTen] && tooLarge(9, rangeOr(settings.Exponents, defaultPowerOfTenExponents).Max) {
+		errs.add("powers.exponents", "must keep powers of ten within %d", MaxPowerResult)
+	}
+}
+
+func validateSequenceConfig(errs *ValidationErrors, sequences models.GameConfigSequences) {
+	for i, kind := range sequences.Kinds {
+		switch kind {
+		case models.SequenceKindArithmetic, models.SequenceKindGeometric, models.SequenceKindSquare, models.SequenceKindFibonacci:
+		default:
+			errs.add(fmt.Sprintf("sequences.kinds[%d]", i), "unknown sequence kind %q", kind)
+		}
+	}
+	if sequences.Length != 0 && (sequences.Length < MinSequenceLength || sequences.Length > MaxSequenceLength) {
+		errs.add("sequences.length", "must be 0 for default or between %d and %d", MinSequenceLength, MaxSequenceLength)
+	}
+	if sequences.MaxStep < 0 || sequences.MaxStep > MaxSequenceStep {
+		errs.add("sequences.max_step", "must be between 0 and %d (0 for default)", MaxSequenceStep)
+	}
+	if sequences.MaxRatio != 0 && (sequences.MaxRatio < 2 || sequences.MaxRatio > MaxSequenceRatio) {
+		errs.add("sequences.max_ratio", "must be 0 for default or between 2 and %d", MaxSequenceRatio)
+	}
+}
+
+func validateComparisonConfig(errs *ValidationErrors, config models.GameConfig) {
+	multiplies := false
+	for i, operation := range comparisonOperations(config) {
+		if precedence(operation) == leafPrecedence {
+			errs.add(fmt.Sprintf("comparisons.operations[%d]", i), "unknown operation %q", operation)
+		}
+		multiplies = multiplies || precedence(operation) == 2
+	}
+	if multiplies && (abs(config.Range.Min) > MaxMultiplyOperand || abs(config.Range.Max) > MaxMultiplyOperand) {
+		errs.add("range", "must stay within -%d and %d when comparing products or quotients", MaxMultiplyOperand, MaxMultiplyOperand)
+	}
+}
+
+func validateEstimationConfig(errs *ValidationErrors, config models.GameConfig) {
+	settings := estimationSettings(config)
+	multiplies := false
+	for i, operation := range settings.Operations {
+		if precedence(operation) == leafPrecedence {
+			errs.add(fmt.Sprintf("estimation.operations[%d]", i), "unknown operation %q", operation)
+		}
+		multiplies = multiplies || operation == models.GameConfigMethodMultiply
+	}
+	if multiplies && (abs(config.Range.Min) > MaxMultiplyOperand || abs(config.Range.Max) > MaxMultiplyOperand) {
+		errs.add("range", "must stay within -%d and %d when estimating products", MaxMultiplyOperand, MaxMultiplyOperand)
+	}
+	if len(settings.Bands) > MaxErrorBands {
+		errs.add("estimation.bands", "must have at most %d bands", MaxErrorBands)
+	}
+	for i, band := range settings.Bands {
+		field := fmt.Sprintf("estimation.bands[%d]", i)
+		if band.Within < 0 || band.Within > 100 {
+			errs.add(field+".within", "must be between 0 and 100")
+		}
+		if band.Points < 1 || band.Points > MaxBandPoints {
+			errs.add(field+".points", "must be between 1 and %d", MaxBandPoints)
+		}
+	}
+	if settings.DisplayMs < 0 || settings.DisplayMs > MaxEstimationDisplayMs {
+		errs.add("estimation.display_ms", "must be between 0 and %d (0 for default)", MaxEstimationDisplayMs)
+	}
+}
+
+func validateWordProblemConfig(errs *ValidationErrors, config models.GameConfig) {
+	settings := wordSettings(config)
+	if _, ok := wordNames[settings.Locale]; !ok {
+		errs.add("word_problems.locale", "unsupported locale %q", settings.Locale)
+	}
+	if settings.Grade < 0 || settings.Grade > MaxWordGrade {
+		errs.add("word_problems.grade", "must be between 0 and %d (0 for any grade)", MaxWordGrade)
+	}
+	if config.MissingOperand {
+		errs.add("missing_operand", "cannot be combined with word problems")
+	}
+	for _, method := range config.Methods {
+		if len(wordTemplatesFor(wordSettings(config), method)) > 0 && methodConfig(config, method).Range.Min < 0 {
+			errs.add("range.min", "must not be negative for word problems")
+			break
+		}
+	}
+}
+
+func validateTargetConfig(errs *ValidationErrors, targets models.GameConfigTargets) {
+	settings := targetSettings(models.GameConfig{Targets: &targets})
+	dealable := true
+	if settings.Numbers < MinTargetNumbers || settings.Numbers > MaxTargetNumbers {
+		errs.add("targets.numbers", "must be 0 for default or between %d and %d", MinTargetNumbers, MaxTargetNumbers)
+		dealable = false
+	}
+	if *settings.Large < 0 || *settings.Large > len(largeNumbers) || *settings.Large > settings.Numbers {
+		errs.add("targets.large", "must be between 0 and %d, and no more than numbers", len(largeNumbers))
+		dealable = false
+	}
+	if settings.MinTarget < 1 || settings.MaxTarget > MaxTarget || settings.MinTarget > settings.MaxTarget {
+		errs.add("targets", "min_target and max_target must be in order between 1 and %d", MaxTarget)
+	} else if dealable && settings.MinTarget > largestTarget(settings) {
+		errs.add("targets.min_target", "must be at most %d, the most %d numbers with %d large can reach",
+			largestTarget(settings), settings.Numbers, *settings.Large)
+	}
+	if len(settings.Bands) > MaxErrorBands {
+		errs.add("targets.bands", "must have at most %d bands", MaxErrorBands)
+	}
+	for i, band := range settings.Bands {
+		field := fmt.Sprintf("targets.bands[%d]", i)
+		if band.Within < 0 || band.Within > 100 {
+			errs.add(field+".within", "must be between 0 and 100")
+		}
+		if band.Points < 1 || band.Points > MaxBandPoints {
+			errs.add(field+".points", "must be between 1 and %d", MaxBandPoints)
+		}
+	}
+}
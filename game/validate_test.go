@@ -0,0 +1,104 @@
+package game
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/FiveEightyEight/mwfapi/models"
+)
+
+func TestValidateGameConfig(t *testing.T) {
+	basic := func(methods ...models.GameConfigMethod) models.GameConfig {
+		return models.GameConfig{Methods: methods, Range: models.GameConfigRange{Min: 1, Max: 12}}
+	}
+
+	tests := []struct {
+		name   string
+		config models.GameConfig
+		fields []string
+	}{
+		{"valid", basic(models.GameConfigMethodAdd, models.GameConfigMethodMultiply), nil},
+		{"no methods", basic(), []string{"methods"}},
+		{"unknown method", basic("modulo"), []string{"methods[0]"}},
+		{"duplicate method", basic(models.GameConfigMethodAdd, models.GameConfigMethodAdd), []string{"methods[1]"}},
+		{"default problem count", with(basic(models.GameConfigMethodAdd), func(c *models.GameConfig) { c.ProblemCount = 0 }), nil},
+		{"max problem count", with(basic(models.GameConfigMethodAdd), func(c *models.GameConfig) { c.ProblemCount = MaxProblemCount }), nil},
+		{"negative problem count", with(basic(models.GameConfigMethodAdd), func(c *models.GameConfig) { c.ProblemCount = -1 }), []string{"problem_count"}},
+		{"too many problems", with(basic(models.GameConfigMethodAdd), func(c *models.GameConfig) { c.ProblemCount = MaxProblemCount + 1 }), []string{"problem_count"}},
+		{"range out of order", with(basic(models.GameConfigMethodAdd), func(c *models.GameConfig) { c.Range = models.GameConfigRange{Min: 5, Max: 1} }), []string{"range"}},
+		{"range too large", with(basic(models.GameConfigMethodAdd), func(c *models.GameConfig) { c.Range.Max = MaxOperand + 1 }), []string{"range.max"}},
+		{"default decimal places", with(basic(models.GameConfigMethodDecimal), func(c *models.GameConfig) { c.Decimals = &models.GameConfigDecimals{} }), nil},
+		{"too many decimal places", with(basic(models.GameConfigMethodDecimal), func(c *models.GameConfig) {
+			c.Decimals = &models.GameConfigDecimals{Places: MaxDecimalPlaces + 1}
+		}), []string{"decimals.places"}},
+		{"negative sequence step", with(basic(models.GameConfigMethodSequence), func(c *models.GameConfig) {
+			c.Sequences = &models.GameConfigSequences{MaxStep: -1}
+		}), []string{"sequences.max_step"}},
+		{"review without methods", models.GameConfig{Mode: models.GameModeReview}, nil},
+		{"unknown mode", with(basic(models.GameConfigMethodAdd), func(c *models.GameConfig) { c.Mode = "chaos" }), []string{"mode"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fields := errorFields(t, ValidateGameConfig(test.config))
+			for _, field := range test.fields {
+				if !slices.Contains(fields, field) {
+					t.Errorf("expected an error on %s, got %v", field, fields)
+				}
+			}
+			if len(test.fields) == 0 && len(fields) > 0 {
+				t.Errorf("expected no errors, got %v", fields)
+			}
+		})
+	}
+}
+
+func TestValidateDecimalTolerance(t *testing.T) {
+	tests := []struct {
+		name      string
+		tolerance models.Value
+		valid     bool
+	}{
+		{"zero", models.IntegerValue(0), true},
+		{"integer", models.IntegerValue(1), true},
+		{"decimal", models.DecimalValue(5, 2), true},
+		{"negative", models.DecimalValue(-5, 2), false},
+		{"fraction", models.FractionValue(1, 2), false},
+		{"list", models.ListValue(models.IntegerValue(1)), false},
+		{"boolean", models.BooleanValue(true), false},
+		{"relation", models.RelationValue(0), false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tolerance := test.tolerance
+			config := models.GameConfig{
+				Methods:  []models.GameConfigMethod{models.GameConfigMethodDecimal},
+				Range:    models.GameConfigRange{Min: 1, Max: 12},
+				Decimals: &models.GameConfigDecimals{Tolerance: &tolerance},
+			}
+			fields := errorFields(t, ValidateGameConfig(config))
+			if invalid := slices.Contains(fields, "decimals.tolerance"); invalid == test.valid {
+				t.Errorf("expected valid %v, got errors %v", test.valid, fields)
+			}
+		})
+	}
+}
+
+// errorFields lists the fields err reports as invalid.
+func errorFields(t *testing.T, err error) []string {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("expected ValidationErrors, got %v", err)
+	}
+	fields := make([]string, len(errs))
+	for i, fe := range errs {
+		fields[i] = fe.Field
+	}
+	return fields
+}
@@ -0,0 +1,131 @@
+package game
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/FiveEightyEight/mwfapi/models"
+)
+
+// RejectionReason says why a submission earned no points.
+type RejectionReason string
+
+const (
+	RejectionMissingField      RejectionReason = "missing_field"
+	RejectionWrongKind         RejectionReason = "wrong_kind"
+	RejectionUnknownChoice     RejectionReason = "unknown_choice"
+	RejectionInvalidExpression RejectionReason = "invalid_expression"
+	RejectionUnavailableNumber RejectionReason = "unavailable_number"
+	RejectionInexactDivision   RejectionReason = "inexact_division"
+	RejectionIncorrect         RejectionReason = "incorrect"
+)
+
+// Rejection is the error a Validator returns for a submission that earns no
+// points. Field names the part of the submission at fault, if there is one.
+type Rejection struct {
+	Reason  RejectionReason `json:"reason"`
+	Field   string          `json:"field,omitempty"`
+	Message string          `json:"message"`
+}
+
+func (r *Rejection) Error() string {
+	return r.Message
+}
+
+func reject(reason RejectionReason, field, format string, args ...interface{}) *Rejection {
+	return &Rejection{Reason: reason, Field: field, Message: fmt.Sprintf(format, args...)}
+}
+
+// Validator checks a submission to problem and returns the points it earns,
+// or a *Rejection if it earns none.
+type Validator func(config models.GameConfig, problem models.GameProblem, submission models.Submission) (int, error)
+
+// validators holds the methods that check submissions their own way rather
+// than comparing a single answer with the problem's.
+var validators = map[models.GameConfigMethod]Validator{
+	models.GameConfigMethodTarget:     validateTargetSubmission,
+	models.GameConfigMethodFactorPair: validateFactorPair,
+}
+
+// ValidateSubmission returns the points submission earns for problem, or a
+// *Rejection saying why it earns none. A multiple choice submission is
+// checked as if the chosen value had been given as the answer.
+func ValidateSubmission(config models.GameConfig, problem models.GameProblem, submission models.Submission) (int, error) {
+	if len(problem.Choices) > 0 {
+		if submission.Choice == "" {
+			return 0, reject(RejectionMissingField, "choice", "this problem is multiple choice, submit a choice")
+		}
+		answer, ok := ChoiceAnswer(problem, submission.Choice)
+		if !ok {
+			return 0, reject(RejectionUnknownChoice, "choice", "unknown choice %q", submission.Choice)
+		}
+		submission = models.Submission{Answer: answer}
+	}
+
+	if validator, ok := validators[problem.Method]; ok {
+		return validator(config, problem, submission)
+	}
+	return validateAnswer(config, problem, submission)
+}
+
+// validateAnswer scores a single answer against the problem's.
+func validateAnswer(config models.GameConfig, problem models.GameProblem, submission models.Submission) (int, error) {
+	if submission.Answer.IsZero() {
+		return 0, reject(RejectionMissingField, "answer", "an answer is required")
+	}
+	if err := checkAnswerFormat(problem, submission.Answer); err != nil {
+		return 0, err
+	}
+	points := ScoreAnswer(config, problem, submission.Answer)
+	if points == 0 {
+		return 0, reject(RejectionIncorrect, "answer", "%s is not correct", submission.Answer)
+	}
+	return points, nil
+}
+
+// validateTargetSubmission scores the value a target puzzle's expression
+// comes to.
+func validateTargetSubmission(config models.GameConfig, problem models.GameProblem, submission models.Submission) (int, error) {
+	if submission.Expression == "" {
+		return 0, reject(RejectionMissingField, "expression", "this puzzle is answered with an expression")
+	}
+	value, err := TargetAnswer(problem, submission.Expression)
+	if err != nil {
+		return 0, err
+	}
+	points := ScoreAnswer(config, problem, value)
+	if points == 0 {
+		return 0, reject(RejectionIncorrect, "expression", "%s is too far from %d", value, problem.Target)
+	}
+	return points, nil
+}
+
+// validateFactorPair accepts any two whole numbers whose product is the
+// problem's, given as its fields or as a list of two.
+func validateFactorPair(config models.GameConfig, problem models.GameProblem, submission models.Submission) (int, error) {
+	pair := make([]models.Value, len(factorPairFields))
+	if submission.Answer.Kind == models.ValueKindList && len(submission.Answer.Items) == len(pair) {
+		copy(pair, submission.Answer.Items)
+	} else if !submission.Answer.IsZero() {
+		return 0, reject(RejectionWrongKind, "answer", "answer must be a list of %d numbers", len(pair))
+	} else {
+		for i, field := range factorPairFields {
+			value, ok := submission.Fields[field]
+			if !ok || value.IsZero() {
+				return 0, reject(RejectionMissingField, field, "a value for %s is required", field)
+			}
+			pair[i] = value
+		}
+	}
+
+	for i, value := range pair {
+		if value.Kind != models.ValueKindInteger {
+			return 0, reject(RejectionWrongKind, factorPairFields[i], "%s must be a whole number", factorPairFields[i])
+		}
+	}
+	product := new(big.Int).Mul(big.NewInt(pair[0].Numerator), big.NewInt(pair[1].Numerator))
+	if product.Cmp(big.NewInt(int64(problem.Number1))) != 0 {
+		return 0, reject(RejectionIncorrect, "", "%s × %s is %s, not %d", pair[0], pair[1], product, problem.Number1)
+	}
+	return 1, nil
+}
@@ -0,0 +1,61 @@
+package game
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/FiveEightyEight/mwfapi/models"
+)
+
+func TestValidateSubmission(t *testing.T) {
+	add := models.GameProblem{Number1: 2, Number2: 3, Method: models.GameConfigMethodAdd, Answer: models.IntegerValue(5)}
+	prime := isPrimeProblem(7)
+	pair := factorPairProblem(12)
+	choice := add
+	choice.Choices = []models.Choice{{ID: "a", Value: models.IntegerValue(5)}, {ID: "b", Value: models.IntegerValue(6)}}
+	integer := func(n int) models.Value { return models.IntegerValue(n) }
+
+	tests := []struct {
+		name       string
+		problem    models.GameProblem
+		submission models.Submission
+		points     int
+		reason     RejectionReason
+		field      string
+	}{
+		{"right", add, models.Submission{Answer: integer(5)}, 1, "", ""},
+		{"wrong", add, models.Submission{Answer: integer(6)}, 0, RejectionIncorrect, "answer"},
+		{"no answer", add, models.Submission{}, 0, RejectionMissingField, "answer"},
+		{"yes or no as a number", prime, models.Submission{Answer: integer(1)}, 0, RejectionWrongKind, "answer"},
+		{"right choice", choice, models.Submission{Choice: "a"}, 1, "", ""},
+		{"wrong choice", choice, models.Submission{Choice: "b"}, 0, RejectionIncorrect, "answer"},
+		{"no choice", choice, models.Submission{Answer: integer(5)}, 0, RejectionMissingField, "choice"},
+		{"unknown choice", choice, models.Submission{Choice: "z"}, 0, RejectionUnknownChoice, "choice"},
+		{"factor pair", pair, models.Submission{Answer: models.ListValue(integer(2), integer(6))}, 1, "", ""},
+		{"other factor pair", pair, models.Submission{Fields: map[string]models.Value{"first": integer(12), "second": integer(1)}}, 1, "", ""},
+		{"wrong factor pair", pair, models.Submission{Answer: models.ListValue(integer(3), integer(5))}, 0, RejectionIncorrect, ""},
+		{"three factors", pair, models.Submission{Answer: models.ListValue(integer(2), integer(2), integer(3))}, 0, RejectionWrongKind, "answer"},
+		{"one factor", pair, models.Submission{Answer: integer(12)}, 0, RejectionWrongKind, "answer"},
+		{"missing field", pair, models.Submission{Fields: map[string]models.Value{"first": integer(3)}}, 0, RejectionMissingField, "second"},
+		{"fractional factor", pair, models.Submission{Answer: models.ListValue(models.FractionValue(1, 2), integer(24))}, 0, RejectionWrongKind, "first"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			points, err := ValidateSubmission(models.GameConfig{}, test.problem, test.submission)
+			if points != test.points {
+				t.Errorf("expected %d points, got %d", test.points, points)
+			}
+			if test.reason == "" {
+				if err != nil {
+					t.Errorf("expected no rejection, got %v", err)
+				}
+				return
+			}
+			var rejection *Rejection
+			if !errors.As(err, &rejection) || rejection.Reason != test.reason || rejection.Field != test.field {
+				t.Errorf("expected a %s rejection on %q, got %+v", test.reason, test.field, err)
+			}
+		})
+	}
+}
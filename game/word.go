@@ -0,0 +1,203 @@
+package game
+
+import (
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/FiveEightyEight/mwfapi/models"
+)
+
+const (
+	DefaultWordLocale = "en"
+	MaxWordGrade      = 6
+)
+
+// noun is a countable noun in its singular and plural forms. Feminine only
+// matters for locales whose questions agree with the noun's gender.
+type noun struct {
+	one, other string
+	feminine   bool
+}
+
+// wordTemplate is the text of a word problem for one method. Placeholders:
+//
+//	{name}                     a person's name
+//	{a}, {b}                   Number1 and Number2
+//	{a_items}, {b_items}       a number followed by the item noun, pluralized to agree
+//	{item}, {items}            the item noun, singular and plural
+//	{a_containers}, {b_containers}, {container}
+//	                           the same for the container noun
+//	{how_many}                 "How many", agreeing with the item noun where the locale needs it
+type wordTemplate struct {
+	locale             string
+	method             models.GameConfigMethod
+	minGrade, maxGrade int
+	text               string
+	items              []noun
+	containers         []noun
+}
+
+var wordNames = map[string][]string{
+	"en": {"Maya", "Leo", "Sam", "Priya", "Omar", "Ava"},
+	"es": {"Lucía", "Mateo", "Sofía", "Diego", "Valeria", "Pablo"},
+}
+
+// howMany is the start of a "how many" question in each locale, for a
+// masculine and a feminine noun.
+var howMany = map[string][2]string{
+	"en": {"How many", "How many"},
+	"es": {"Cuántos", "Cuántas"},
+}
+
+var wordTemplates = []wordTemplate{
+	{
+		locale: "en", method: models.GameConfigMethodAdd, minGrade: 1, maxGrade: 3,
+		text:  "{name} has {a_items}. A friend gives {name} {b} more. How many {items} does {name} have now?",
+		items: []noun{{one: "apple", other: "apples"}, {one: "marble", other: "marbles"}, {one: "sticker", other: "stickers"}},
+	},
+	{
+		locale: "en", method: models.GameConfigMethodAdd, minGrade: 4, maxGrade: 6,
+		text:  "A bakery baked {a_items} on Monday and {b_items} on Tuesday. How many {items} did it bake altogether?",
+		items: []noun{{one: "loaf", other: "loaves"}, {one: "cake", other: "cakes"}, {one: "cookie", other: "cookies"}},
+	},
+	{
+		locale: "en", method: models.GameConfigMethodSubtract, minGrade: 1, maxGrade: 3,
+		text:  "{name} has {a_items} and gives away {b}. How many {items} does {name} have left?",
+		items: []noun{{one: "apple", other: "apples"}, {one: "balloon", other: "balloons"}, {one: "pencil", other: "pencils"}},
+	},
+	{
+		locale: "en", method: models.GameConfigMethodSubtract, minGrade: 4, maxGrade: 6,
+		text:  "A library has {a_items} and readers borrow {b_items}. How many {items} are left on the shelves?",
+		items: []noun{{one: "book", other: "books"}, {one: "magazine", other: "magazines"}},
+	},
+	{
+		locale: "en", method: models.GameConfigMethodMultiply, minGrade: 2, maxGrade: 4,
+		text:       "{name} has {a_containers} with {b_items} in each {container}. How many {items} does {name} have in total?",
+		items:      []noun{{one: "marble", other: "marbles"}, {one: "crayon", other: "crayons"}},
+		containers: []noun{{one: "bag", other: "bags"}, {one: "box", other: "boxes"}},
+	},
+	{
+		locale: "en", method: models.GameConfigMethodMultiply, minGrade: 5, maxGrade: 6,
+		text:       "A theater has {a_containers} with {b_items} in each {container}. How many {items} are there altogether?",
+		items:      []noun{{one: "seat", other: "seats"}},
+		containers: []noun{{one: "row", other: "rows"}},
+	},
+	{
+		locale: "en", method: models.GameConfigMethodDivide, minGrade: 2, maxGrade: 4,
+		text:       "{name} shares {a_items} equally among {b_containers}. How many {items} does each {container} get?",
+		items:      []noun{{one: "cookie", other: "cookies"}, {one: "card", other: "cards"}},
+		containers: []noun{{one: "friend", other: "friends"}},
+	},
+	{
+		locale: "en", method: models.GameConfigMethodDivide, minGrade: 5, maxGrade: 6,
+		text:       "A farmer packs {a_items} into {b_containers}, with the same number in each {container}. How many {items} go in each {container}?",
+		items:      []noun{{one: "egg", other: "eggs"}, {one: "peach", other: "peaches"}},
+		containers: []noun{{one: "crate", other: "crates"}},
+	},
+	{
+		locale: "es", method: models.GameConfigMethodAdd, minGrade: 1, maxGrade: 3,
+		text:  "{name} tiene {a_items}. Una amiga le da {b} más. ¿{how_many} {items} tiene {name} ahora?",
+		items: []noun{{one: "manzana", other: "manzanas", feminine: true}, {one: "canica", other: "canicas", feminine: true}, {one: "lápiz", other: "lápices"}},
+	},
+	{
+		locale: "es", method: models.GameConfigMethodAdd, minGrade: 4, maxGrade: 6,
+		text:  "Una panadería horneó {a_items} el lunes y {b_items} el martes. ¿{how_many} {items} horneó en total?",
+		items: []noun{{one: "pan", other: "panes"}, {one: "pastel", other: "pasteles"}, {one: "galleta", other: "galletas", feminine: true}},
+	},
+	{
+		locale: "es", method: models.GameConfigMethodSubtract, minGrade: 1, maxGrade: 6,
+		text:  "{name} tiene {a_items} y regala {b}. ¿{how_many} {items} le quedan?",
+		items: []noun{{one: "manzana", other: "manzanas", feminine: true}, {one: "globo", other: "globos"}, {one: "lápiz", other: "lápices"}},
+	},
+	{
+		locale: "es", method: models.GameConfigMethodMultiply, minGrade: 2, maxGrade: 6,
+		text:       "{name} tiene {a_containers} con {b_items} en cada {container}. ¿{how_many} {items} tiene en total?",
+		items:      []noun{{one: "canica", other: "canicas", feminine: true}, {one: "crayón", other: "crayones"}},
+		containers: []noun{{one: "bolsa", other: "bolsas", feminine: true}, {one: "caja", other: "cajas", feminine: true}},
+	},
+	{
+		locale: "es", method: models.GameConfigMethodDivide, minGrade: 2, maxGrade: 6,
+		text:       "{name} reparte {a_items} en partes iguales entre {b_containers}. ¿{how_many} {items} recibe cada {container}?",
+		items:      []noun{{one: "galleta", other: "galletas", feminine: true}, {one: "cromo", other: "cromos"}},
+		containers: []noun{{one: "amigo", other: "amigos"}},
+	},
+}
+
+// pluralRules reports, for each locale, whether a count takes the singular.
+var pluralRules = map[string]func(n int) bool{
+	"en": func(n int) bool { return n == 1 },
+	"es": func(n int) bool { return n == 1 },
+}
+
+func wordSettings(config models.GameConfig) models.GameConfigWordProblems {
+	settings := *config.WordProblems
+	if settings.Locale == "" {
+		settings.Locale = DefaultWordLocale
+	}
+	return settings
+}
+
+// wordTemplatesFor returns the templates for method in the config's locale
+// and grade, or every grade's if none suit the grade.
+func wordTemplatesFor(settings models.GameConfigWordProblems, method models.GameConfigMethod) []wordTemplate {
+	var forLocale, forGrade []wordTemplate
+	for _, template := range wordTemplates {
+		if template.locale != settings.Locale || template.method != method {
+			continue
+		}
+		forLocale = append(forLocale, template)
+		if settings.Grade == 0 || (template.minGrade <= settings.Grade && settings.Grade <= template.maxGrade) {
+			forGrade = append(forGrade, template)
+		}
+	}
+	if len(forGrade) == 0 {
+		return forLocale
+	}
+	return forGrade
+}
+
+// renderWordProblem writes problem as a word problem, or returns "" if there
+// is no template for its method.
+func renderWordProblem(config models.GameConfig, problem models.GameProblem, random *rand.Rand) string {
+	settings := wordSettings(config)
+	templates := wordTemplatesFor(settings, problem.Method)
+	if len(templates) == 0 {
+		return ""
+	}
+	template := templates[random.Intn(len(templates))]
+
+	names := wordNames[settings.Locale]
+	name := names[random.Intn(len(names))]
+	item := template.items[random.Intn(len(template.items))]
+	var container noun
+	if len(template.containers) > 0 {
+		container = template.containers[random.Intn(len(template.containers))]
+	}
+
+	isOne := pluralRules[settings.Locale]
+	count := func(n int, word noun) string {
+		if isOne(n) {
+			return strconv.Itoa(n) + " " + word.one
+		}
+		return strconv.Itoa(n) + " " + word.other
+	}
+	question := howMany[settings.Locale][0]
+	if item.feminine {
+		question = howMany[settings.Locale][1]
+	}
+
+	return strings.NewReplacer(
+		"{name}", name,
+		"{a}", strconv.Itoa(problem.Number1),
+		"{b}", strconv.Itoa(problem.Number2),
+		"{a_items}", count(problem.Number1, item),
+		"{b_items}", count(problem.Number2, item),
+		"{item}", item.one,
+		"{items}", item.other,
+		"{a_containers}", count(problem.Number1, container),
+		"{b_containers}", count(problem.Number2, container),
+		"{container}", container.one,
+		"{how_many}", question,
+	).Replace(template.text)
+}
@@ -0,0 +1,61 @@
+package game
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/FiveEightyEight/mwfapi/models"
+)
+
+func TestWordTemplates(t *testing.T) {
+	for _, template := range wordTemplates {
+		if _, ok := wordNames[template.locale]; !ok {
+			t.Errorf("%s %s template: no names for its locale", template.locale, template.method)
+		}
+		if strings.Contains(template.text, "container") && len(template.containers) == 0 {
+			t.Errorf("%s %s template: uses a container but has none", template.locale, template.method)
+		}
+	}
+}
+
+func TestRenderWordProblem(t *testing.T) {
+	config := models.GameConfig{
+		Methods:      basicMethods,
+		Range:        models.GameConfigRange{Min: 1, Max: 12},
+		WordProblems: &models.GameConfigWordProblems{Locale: "es", Grade: 2},
+	}
+	for seed := int64(0); seed < 20; seed++ {
+		for _, problem := range GenerateGameProblems(config, seed) {
+			if problem.Text == "" || strings.ContainsAny(problem.Text, "{}") {
+				t.Fatalf("seed %d: %s was not fully worded, got %q", seed, problemKey(problem), problem.Text)
+			}
+			if !strings.Contains(problem.Text, "¿") {
+				t.Fatalf("seed %d: expected a Spanish question, got %q", seed, problem.Text)
+			}
+			if problem.Method == models.GameConfigMethodDivide && problem.Number1 != problem.Answer.Int()*problem.Number2 {
+				t.Fatalf("seed %d: expected things to share out exactly, got %q", seed, problem.Text)
+			}
+		}
+	}
+
+	// Counts agree with their nouns
+	config.WordProblems = &models.GameConfigWordProblems{Locale: "en", Grade: 1}
+	nounAfter := func(text, count string) string {
+		_, rest, _ := strings.Cut(text, "has "+count+" ")
+		return strings.Fields(rest)[0]
+	}
+	one := models.GameProblem{Number1: 1, Number2: 1, Method: models.GameConfigMethodSubtract, Answer: models.IntegerValue(0)}
+	if text := renderWordProblem(config, one, rand.New(rand.NewSource(1))); strings.HasSuffix(nounAfter(text, "1"), "s") {
+		t.Errorf("expected a singular noun after 1, got %q", text)
+	}
+	many := models.GameProblem{Number1: 5, Number2: 2, Method: models.GameConfigMethodSubtract, Answer: models.IntegerValue(3)}
+	if text := renderWordProblem(config, many, rand.New(rand.NewSource(1))); !strings.HasSuffix(nounAfter(text, "5"), "s") {
+		t.Errorf("expected a plural noun after 5, got %q", text)
+	}
+
+	// Methods without templates stay as they are
+	if text := renderWordProblem(config, squareRootProblem(4), rand.New(rand.NewSource(1))); text != "" {
+		t.Errorf("expected no wording for a square root, got %q", text)
+	}
+}
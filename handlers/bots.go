@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"time"
+
+	"github.com/FiveEightyEight/mwfapi/db"
+	"github.com/FiveEightyEight/mwfapi/game"
+	"github.com/FiveEightyEight/mwfapi/models"
+	"github.com/google/uuid"
+)
+
+const maxBotsPerSession = 8
+
+// newBot builds a bot from an add_bot payload. The payload either names a
+// preset with "skill" or carries a full "profile".
+func newBot(gameSession *models.GameSession, payload map[string]interface{}) (*models.Bot, error) {
+	if len(gameSession.Bots) >= maxBotsPerSession {
+		return nil, fmt.Errorf("session already has %d bots", maxBotsPerSession)
+	}
+
+	profile := game.BotSkills["medium"]
+	if skill, ok := payload["skill"].(string); ok {
+		preset, ok := game.BotSkills[skill]
+		if !ok {
+			return nil, fmt.Errorf("unknown bot skill %q", skill)
+		}
+		profile = preset
+	}
+	if profilePayload, ok := payload["profile"]; ok {
+		if err := decodePayload(profilePayload, &profile); err != nil {
+			return nil, err
+		}
+	}
+	if !game.ValidateBotProfile(profile) {
+		return nil, errors.New("invalid bot profile")
+	}
+
+	username, _ := payload["username"].(string)
+	if username == "" {
+		username = fmt.Sprintf("bot %d", len(gameSession.Bots)+1)
+	}
+
+	return &models.Bot{
+		ID:       uuid.New(),
+		Username: username,
+		Profile:  profile,
+	}, nil
+}
+
+// runBot plays a bot in a game session until it is removed or every human
+// player has left. Answers go through handleGameEvent like any other player's.
+func runBot(rdb *db.RedisClient, sessionID uuid.UUID, bot models.Bot) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	updates, err := rdb.SubscribeToGameSession(ctx, sessionID)
+	if err != nil {
+		log.Printf("Bot %s failed to subscribe to game session: %v", bot.ID, err)
+		return
+	}
+	log.Printf("Bot %s joined game session %s", bot.ID, sessionID)
+
+	random := rand.New(rand.NewSource(time.Now().UnixNano()))
+	answers := make(chan int)
+	var timer *time.Timer
+	problemIndex := -1
+	var submission map[string]interface{}
+
+	for {
+		select {
+		case gameSession, ok := <-updates:
+			if !ok {
+				return
+			}
+			if !isPlayer(gameSession, bot.ID) || countHumanPlayers(gameSession.Players) == 0 {
+				log.Printf("Bot %s leaving game session %s", bot.ID, sessionID)
+				if timer != nil {
+					timer.Stop()
+				}
+				return
+			}
+			if gameSession.Status != models.GameSessionStatusInProgress {
+				if timer != nil {
+					timer.Stop()
+				}
+				problemIndex = -1
+				continue
+			}
+			current, problem := botProblem(gameSession, bot.ID)
+			if current == problemIndex || problem == nil {
+				continue
+			}
+
+			// A new problem is up, think about it for a while then answer
+			if timer != nil {
+				timer.Stop()
+			}
+			problemIndex = current
+			switch {
+			case len(problem.Choices) > 0:
+				submission = map[string]interface{}{"choice": game.BotChoice(*problem, bot.Profile, random)}
+			case problem.Method == models.GameConfigMethodTarget:
+				submission = map[string]interface{}{"expression": game.BotExpression(*problem, bot.Profile, random)}
+			default:
+				submission = map[string]interface{}{"answer": game.BotAnswer(*problem, bot.Profile, random)}
+			}
+			index := problemIndex
+			timer = time.AfterFunc(game.BotResponseDelay(bot.Profile, random), func() {
+				select {
+				case answers <- index:
+				case <-ctx.Done():
+				}
+			})
+		case index := <-answers:
+			if index != problemIndex {
+				continue
+			}
+			err := handleGameEvent(ctx, rdb, sessionID, bot.ID, "submit_answer", submission)
+			// Wrong answers are expected, only log submissions that were malformed
+			var rejection *game.Rejection
+			if err != nil && !(errors.As(err, &rejection) && rejection.Reason == game.RejectionIncorrect) {
+				log.Printf("Bot %s answer rejected: %v", bot.ID, err)
+			}
+		}
+	}
+}
+
+// botProblem returns the problem a bot should be answering and its position
+// in the game: the shared current problem, or the bot's own when every
+// player has their own. The problem is nil if there is none left.
+func botProblem(gameSession *models.GameSession, botID uuid.UUID) (int, *models.GameProblem) {
+	if game.PerPlayer(gameSession.GameConfig) {
+		for _, progress := range gameSession.Progress {
+			if progress.UserID == botID {
+				return progress.Answered, progress.Problem
+			}
+		}
+		return -1, nil
+	}
+	if gameSession.CurrentProblemIndex >= len(gameSession.Problems) {
+		return gameSession.CurrentProblemIndex, nil
+	}
+	return gameSession.CurrentProblemIndex, &gameSession.Problems[gameSession.CurrentProblemIndex]
+}
+
+func isPlayer(gameSession *models.GameSession, userID uuid.UUID) bool {
+	for _, player := range gameSession.Players {
+		if player.ID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+func countHumanPlayers(players []models.User) int {
+	count := 0
+	for _, player := range players {
+		if !player.IsBot {
+			count++
+		}
+	}
+	return count
+}
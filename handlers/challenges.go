@@ -0,0 +1,267 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/FiveEightyEight/mwfapi/db"
+	"github.com/FiveEightyEight/mwfapi/game"
+	"github.com/FiveEightyEight/mwfapi/models"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+const challengeTTL = 7 * 24 * time.Hour
+
+func CreateChallenge(rdb *db.RedisClient) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var req struct {
+			GameConfig models.GameConfig `json:"game_config"`
+			Seed       *int64            `json:"seed"`
+		}
+		if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request format"})
+		}
+
+		if err := game.ValidateGameConfig(req.GameConfig); err != nil {
+			return c.JSON(http.StatusBadRequest, configErrorResponse(err))
+		}
+		// Both players must face the same problems
+		if game.PerPlayer(req.GameConfig) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Challenges must give both players the same problems"})
+		}
+
+		seed := game.NewSeed()
+		if req.Seed != nil {
+			if *req.Seed < 0 || *req.Seed > game.MaxSeed {
+				return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid seed"})
+			}
+			seed = *req.Seed
+		}
+
+		now := time.Now()
+		challenge := &models.Challenge{
+			ID:         uuid.New(),
+			GameConfig: req.GameConfig,
+			Seed:       seed,
+			Problems:   game.GenerateGameProblems(req.GameConfig, seed),
+			Status:     models.ChallengeStatusPending,
+			Challenger: models.AttemptResult{
+				UserID:    uuid.MustParse(c.Get("userID").(string)),
+				Username:  c.Get("username").(string),
+				Answers:   []models.AttemptAnswer{},
+				StartedAt: now,
+			},
+			CreatedAt: now,
+			ExpiresAt: now.Add(challengeTTL),
+		}
+
+		err := rdb.CreateChallenge(c.Request().Context(), challenge)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to create challenge"})
+		}
+
+		return c.JSON(http.StatusCreated, map[string]interface{}{
+			"challenge": publicChallenge(challenge),
+			"problems":  game.HideAnswers(challengeProblems(challenge)),
+		})
+	}
+}
+
+func AcceptChallenge(rdb *db.RedisClient) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		challenge, errResponse := loadChallenge(c, rdb)
+		if challenge == nil {
+			return errResponse
+		}
+
+		userID := uuid.MustParse(c.Get("userID").(string))
+		if challenge.Challenger.UserID == userID {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "You cannot accept your own challenge"})
+		}
+
+		switch challenge.Status {
+		case models.ChallengeStatusPending:
+			return c.JSON(http.StatusConflict, map[string]string{"error": "Challenger has not finished playing yet"})
+		case models.ChallengeStatusOpen:
+			challenge.Status = models.ChallengeStatusAccepted
+			challenge.Opponent = &models.AttemptResult{
+				UserID:    userID,
+				Username:  c.Get("username").(string),
+				Answers:   []models.AttemptAnswer{},
+				StartedAt: time.Now(),
+			}
+			accepted, err := rdb.TransitionChallenge(c.Request().Context(), challenge, models.ChallengeStatusOpen)
+			if err != nil {
+				return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to accept challenge"})
+			}
+			if !accepted {
+				return c.JSON(http.StatusConflict, map[string]string{"error": "Challenge has already been accepted"})
+			}
+		default:
+			// The opponent may pick their unfinished attempt back up, nobody else can join
+			if challenge.Opponent.UserID != userID || challenge.Opponent.CompletedAt != nil {
+				return c.JSON(http.StatusConflict, map[string]string{"error": "Challenge has already been accepted"})
+			}
+		}
+
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"challenge": publicChallenge(challenge),
+			"problems":  game.HideAnswers(challengeProblems(challenge)),
+		})
+	}
+}
+
+func SubmitChallenge(rdb *db.RedisClient) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var req struct {
+			Answers []models.AttemptAnswer `json:"answers"`
+		}
+		if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+		}
+
+		challenge, errResponse := loadChallenge(c, rdb)
+		if challenge == nil {
+			return errResponse
+		}
+
+		userID := uuid.MustParse(c.Get("userID").(string))
+		var attempt *models.AttemptResult
+		var nextStatus models.ChallengeStatus
+		switch {
+		case challenge.Status == models.ChallengeStatusPending && challenge.Challenger.UserID == userID:
+			attempt = &challenge.Challenger
+			nextStatus = models.ChallengeStatusOpen
+		case challenge.Status == models.ChallengeStatusAccepted && challenge.Opponent.UserID == userID:
+			attempt = challenge.Opponent
+			nextStatus = models.ChallengeStatusCompleted
+		default:
+			return c.JSON(http.StatusConflict, map[string]string{"error": "No attempt in progress for this challenge"})
+		}
+
+		problems := challengeProblems(challenge)
+		if len(req.Answers) > len(problems) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Too many answers"})
+		}
+
+		completedAt := time.Now()
+		attempt.Answers, attempt.Points = game.GradeAnswers(challenge.GameConfig, problems, req.Answers)
+		attempt.TotalTimeMs = int(completedAt.Sub(attempt.StartedAt).Milliseconds())
+		attempt.CompletedAt = &completedAt
+		previousStatus := challenge.Status
+		challenge.Status = nextStatus
+
+		submitted, err := rdb.TransitionChallenge(c.Request().Context(), challenge, previousStatus)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to save challenge"})
+		}
+		if !submitted {
+			return c.JSON(http.StatusConflict, map[string]string{"error": "Challenge attempt already submitted"})
+		}
+		recordAttemptAnswers(c.Request().Context(), rdb, userID, challenge.GameConfig, problems, attempt.Answers)
+
+		return c.JSON(http.StatusOK, challengeResults(challenge, userID))
+	}
+}
+
+func GetChallenge(rdb *db.RedisClient) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		challenge, errResponse := loadChallenge(c, rdb)
+		if challenge == nil {
+			return errResponse
+		}
+
+		return c.JSON(http.StatusOK, challengeResults(challenge, uuid.MustParse(c.Get("userID").(string))))
+	}
+}
+
+// loadChallenge fetches the challenge named in the path. When it returns nil
+// the error response has already been written.
+func loadChallenge(c echo.Context, rdb *db.RedisClient) (*models.Challenge, error) {
+	challengeID, err := uuid.Parse(c.Param("challenge_id"))
+	if err != nil {
+		return nil, c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid challenge ID"})
+	}
+
+	challenge, err := rdb.GetChallenge(c.Request().Context(), challengeID)
+	if err != nil {
+		return nil, c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve challenge"})
+	}
+	if challenge == nil {
+		return nil, c.JSON(http.StatusNotFound, map[string]string{"error": "Challenge not found or expired"})
+	}
+
+	return challenge, nil
+}
+
+// challengeProblems returns the problems generated when the challenge was
+// created. Challenges saved before problems were stored regenerate them from
+// the seed.
+func challengeProblems(challenge *models.Challenge) []models.GameProblem {
+	if challenge.Problems == nil {
+		return game.GenerateGameProblems(challenge.GameConfig, challenge.Seed)
+	}
+	return challenge.Problems
+}
+
+// publicChallenge hides the problems and the seed they were generated from, so
+// the answers can't be worked out ahead of playing them.
+func publicChallenge(challenge *models.Challenge) models.Challenge {
+	view := *challenge
+	view.Seed = 0
+	view.Problems = nil
+	return view
+}
+
+// challengeResults compares both players problem by problem. The problems are
+// only revealed to a viewer who has finished their own attempt.
+func challengeResults(challenge *models.Challenge, viewerID uuid.UUID) map[string]interface{} {
+	response := map[string]interface{}{
+		"challenge": publicChallenge(challenge),
+	}
+
+	viewerDone := challenge.Challenger.UserID == viewerID && challenge.Challenger.CompletedAt != nil
+	if challenge.Opponent != nil && challenge.Opponent.UserID == viewerID && challenge.Opponent.CompletedAt != nil {
+		viewerDone = true
+	}
+	if !viewerDone {
+		return response
+	}
+
+	problems := challengeProblems(challenge)
+	results := make([]models.ChallengeProblemResult, len(problems))
+	for i, problem := range problems {
+		results[i].Problem = problem
+		if i < len(challenge.Challenger.Answers) {
+			results[i].Challenger = &challenge.Challenger.Answers[i]
+		}
+		if challenge.Opponent != nil && i < len(challenge.Opponent.Answers) {
+			results[i].Opponent = &challenge.Opponent.Answers[i]
+		}
+	}
+	response["results"] = results
+
+	if challenge.Status == models.ChallengeStatusCompleted {
+		response["winner"] = challengeWinner(challenge)
+	}
+
+	return response
+}
+
+// challengeWinner picks the player with more points, breaking ties on total
+// time. It returns nil for a dead heat.
+func challengeWinner(challenge *models.Challenge) *uuid.UUID {
+	challenger, opponent := challenge.Challenger, challenge.Opponent
+	switch {
+	case challenger.Points > opponent.Points:
+		return &challenger.UserID
+	case opponent.Points > challenger.Points:
+		return &opponent.UserID
+	case challenger.TotalTimeMs < opponent.TotalTimeMs:
+		return &challenger.UserID
+	case opponent.TotalTimeMs < challenger.TotalTimeMs:
+		return &opponent.UserID
+	}
+	return nil
+}
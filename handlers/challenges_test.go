@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/FiveEightyEight/mwfapi/game"
+	"github.com/FiveEightyEight/mwfapi/models"
+	"github.com/google/uuid"
+)
+
+func TestChallengeWinner(t *testing.T) {
+	challenger := models.AttemptResult{UserID: uuid.New(), Points: 8, TotalTimeMs: 30000}
+	opponent := models.AttemptResult{UserID: uuid.New(), Points: 8, TotalTimeMs: 30000}
+	challenge := &models.Challenge{Challenger: challenger, Opponent: &opponent}
+
+	if winner := challengeWinner(challenge); winner != nil {
+		t.Errorf("expected a dead heat, got %v", *winner)
+	}
+	opponent.TotalTimeMs = 25000
+	if winner := challengeWinner(challenge); winner == nil || *winner != opponent.UserID {
+		t.Errorf("expected the faster opponent to win a tie on points, got %v", winner)
+	}
+	challenge.Challenger.Points = 9
+	if winner := challengeWinner(challenge); winner == nil || *winner != challenger.UserID {
+		t.Errorf("expected more points to beat a faster time, got %v", winner)
+	}
+}
+
+func TestChallengeResults(t *testing.T) {
+	completed := time.Now()
+	challenge := &models.Challenge{
+		GameConfig: game.DailyChallengeConfig,
+		Seed:       7,
+		Problems:   game.GenerateGameProblems(game.DailyChallengeConfig, 7),
+		Status:     models.ChallengeStatusAccepted,
+		Challenger: models.AttemptResult{UserID: uuid.New(), CompletedAt: &completed},
+		Opponent:   &models.AttemptResult{UserID: uuid.New()},
+	}
+
+	// The opponent can't see the problems before playing them
+	response := challengeResults(challenge, challenge.Opponent.UserID)
+	if _, ok := response["results"]; ok {
+		t.Error("expected no results for a viewer who hasn't finished")
+	}
+	if view := response["challenge"].(models.Challenge); view.Seed != 0 || view.Problems != nil {
+		t.Error("expected the problems and their seed to be hidden")
+	}
+
+	response = challengeResults(challenge, challenge.Challenger.UserID)
+	results, ok := response["results"].([]models.ChallengeProblemResult)
+	if !ok || len(results) != game.DailyChallengeConfig.ProblemCount {
+		t.Fatalf("expected a result for each problem, got %v", response["results"])
+	}
+	if !reflect.DeepEqual(results[0].Problem, challenge.Problems[0]) {
+		t.Errorf("expected the stored problems, got %+v", results[0].Problem)
+	}
+	if _, ok := response["winner"]; ok {
+		t.Error("expected no winner before both have played")
+	}
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/FiveEightyEight/mwfapi/db"
+	"github.com/FiveEightyEight/mwfapi/game"
+	"github.com/FiveEightyEight/mwfapi/models"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+const defaultLeaderboardLimit = 20
+
+func GetDailyChallenge(rdb *db.RedisClient) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		userID := uuid.MustParse(c.Get("userID").(string))
+		date := game.DailyChallengeDate(time.Now())
+
+		attempt, err := rdb.GetDailyAttempt(c.Request().Context(), date, userID)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve daily attempt"})
+		}
+
+		return c.JSON(http.StatusOK, models.DailyChallenge{
+			Date:       date,
+			GameConfig: game.DailyChallengeConfig,
+			Attempt:    attempt,
+		})
+	}
+}
+
+func StartDailyChallenge(rdb *db.RedisClient) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		userID := uuid.MustParse(c.Get("userID").(string))
+		username := c.Get("username").(string)
+		date := game.DailyChallengeDate(time.Now())
+		ctx := c.Request().Context()
+
+		attempt := &models.AttemptResult{
+			UserID:    userID,
+			Username:  username,
+			Answers:   []models.AttemptAnswer{},
+			StartedAt: time.Now(),
+		}
+		started, err := rdb.StartDailyAttempt(ctx, date, attempt)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to start daily challenge"})
+		}
+
+		// An attempt that was started but never submitted can be resumed, a finished one can't be replayed
+		if !started {
+			attempt, err = rdb.GetDailyAttempt(ctx, date, userID)
+			if err != nil || attempt == nil {
+				return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve daily attempt"})
+			}
+			if attempt.CompletedAt != nil {
+				return c.JSON(http.StatusConflict, map[string]string{"error": "Daily challenge already played today"})
+			}
+		}
+
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"date":       date,
+			"started_at": attempt.StartedAt,
+			"problems":   game.HideAnswers(game.DailyChallengeProblems(date)),
+		})
+	}
+}
+
+func SubmitDailyChallenge(rdb *db.RedisClient) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var req struct {
+			Date    string                 `json:"date"`
+			Answers []models.AttemptAnswer `json:"answers"`
+		}
+		if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+		}
+
+		// The date comes from the start response so an attempt begun just before midnight still counts
+		if req.Date == "" {
+			req.Date = game.DailyChallengeDate(time.Now())
+		}
+		if _, err := time.Parse(time.DateOnly, req.Date); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid date"})
+		}
+
+		userID := uuid.MustParse(c.Get("userID").(string))
+		ctx := c.Request().Context()
+
+		attempt, err := rdb.GetDailyAttempt(ctx, req.Date, userID)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve daily attempt"})
+		}
+		if attempt == nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Daily challenge has not been started"})
+		}
+		if attempt.CompletedAt != nil {
+			return c.JSON(http.StatusConflict, map[string]string{"error": "Daily challenge already submitted"})
+		}
+
+		problems := game.DailyChallengeProblems(req.Date)
+		if len(req.Answers) > len(problems) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Too many answers"})
+		}
+
+		completedAt := time.Now()
+		attempt.Answers, attempt.Points = game.GradeAnswers(game.DailyChallengeConfig, problems, req.Answers)
+		attempt.TotalTimeMs = int(completedAt.Sub(attempt.StartedAt).Milliseconds())
+		attempt.CompletedAt = &completedAt
+
+		completed, err := rdb.CompleteDailyAttempt(ctx, req.Date, attempt)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to save daily attempt"})
+		}
+		if !completed {
+			return c.JSON(http.StatusConflict, map[string]string{"error": "Daily challenge already submitted"})
+		}
+		recordAttemptAnswers(ctx, rdb, userID, game.DailyChallengeConfig, problems, attempt.Answers)
+
+		return c.JSON(http.StatusOK, attempt)
+	}
+}
+
+func GetDailyLeaderboard(rdb *db.RedisClient) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		date := c.QueryParam("date")
+		if date == "" {
+			date = game.DailyChallengeDate(time.Now())
+		}
+		if _, err := time.Parse(time.DateOnly, date); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid date"})
+		}
+
+		limit := int64(defaultLeaderboardLimit)
+		if limitStr := c.QueryParam("limit"); limitStr != "" {
+			parsed, err := strconv.ParseInt(limitStr, 10, 64)
+			if err != nil || parsed < 1 || parsed > 100 {
+				return c.JSON(http.StatusBadRequest, map[string]string{"error": "Limit must be between 1 and 100"})
+			}
+			limit = parsed
+		}
+
+		leaderboard, err := rdb.GetDailyLeaderboard(c.Request().Context(), date, limit)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve daily leaderboard"})
+		}
+
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"date":        date,
+			"leaderboard": leaderboard,
+		})
+	}
+}
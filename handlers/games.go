@@ -3,8 +3,10 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
+	"slices"
 	"time"
 
 	"github.com/FiveEightyEight/mwfapi/db"
@@ -15,6 +17,10 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
+// errGameNotInProgress aborts a session update that only applies while the
+// game is being played.
+var errGameNotInProgress = errors.New("the game is not in progress")
+
 var upgrader = websocket.Upgrader{
 	CheckOrigin: func(r *http.Request) bool {
 		return true // Allow all origins for now. Change in production :)
@@ -27,6 +33,7 @@ func CreateGame(rdb *db.RedisClient) echo.HandlerFunc {
 		var req struct {
 			Name       string            `json:"name"`
 			GameConfig models.GameConfig `json:"game_config"`
+			Seed       *int64            `json:"seed"`
 		}
 		if err := c.Bind(&req); err != nil {
 			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request format"})
@@ -37,6 +44,20 @@ func CreateGame(rdb *db.RedisClient) echo.HandlerFunc {
 			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Game name is required"})
 		}
 
+		// Validate the game config before anything is saved
+		if err := game.ValidateGameConfig(req.GameConfig); err != nil {
+			return c.JSON(http.StatusBadRequest, configErrorResponse(err))
+		}
+
+		// Reuse a shared seed to replay a known problem set, otherwise pick a fresh one
+		seed := game.NewSeed()
+		if req.Seed != nil {
+			if *req.Seed < 0 || *req.Seed > game.MaxSeed {
+				return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid seed"})
+			}
+			seed = *req.Seed
+		}
+
 		// Create a new game
 		newGame := &models.Game{
 			ID:   uuid.New(),
@@ -50,19 +71,26 @@ func CreateGame(rdb *db.RedisClient) echo.HandlerFunc {
 		}
 
 		// Generate game problems
-		problems := game.GenerateGameProblems(req.GameConfig)
+		problems := roundProblems(req.GameConfig, seed)
 
-		// Create a new game session
+		// Create a new game session, hosted by the user creating it
 		gameSession := &models.GameSession{
 			ID:                  uuid.New(),
 			Name:                req.Name,
 			GameID:              newGame.ID,
+			HostID:              uuid.MustParse(c.Get("userID").(string)),
 			Status:              "waiting",
 			Players:             []models.User{},
+			Bots:                []models.Bot{},
 			Scores:              []models.Score{},
 			GameConfig:          req.GameConfig,
+			Seed:                seed,
 			Problems:            problems,
 			CurrentProblemIndex: 0,
+			Round:               1,
+			History:             []models.GameRound{},
+			Standings:           []models.Standing{},
+			RematchVotes:        []uuid.UUID{},
 		}
 
 		// Save the game session to Redis
@@ -71,7 +99,7 @@ func CreateGame(rdb *db.RedisClient) echo.HandlerFunc {
 			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to create game session"})
 		}
 
-		return c.JSON(http.StatusCreated, gameSession)
+		return c.JSON(http.StatusCreated, sessionView(gameSession))
 	}
 }
 
@@ -81,7 +109,11 @@ func GetActiveGameSessions(rdb *db.RedisClient) echo.HandlerFunc {
 		if err != nil {
 			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve active game sessions"})
 		}
-		return c.JSON(http.StatusOK, activeSessions)
+		views := make([]*models.GameSession, len(activeSessions))
+		for i, gameSession := range activeSessions {
+			views[i] = sessionView(gameSession)
+		}
+		return c.JSON(http.StatusOK, views)
 	}
 }
 
@@ -161,11 +193,27 @@ func ConnectToGameSession(rdb *db.RedisClient, upgrader websocket.Upgrader) echo
 				ID:       uuid.MustParse(userID),
 				Username: username,
 			}
-			gameSession.Players = append(gameSession.Players, newPlayer)
 
-			// Update the game session in Redis
-			err = rdb.UpdateGameSession(c.Request().Context(), gameSession)
-			if err != nil {
+			// Update the game session in Redis, as it is by then, so players
+			// joining at the same time are all kept
+			var joinErr error
+			gameSession, err = rdb.ModifyGameSession(c.Request().Context(), uuid.MustParse(sessionID), func(current *models.GameSession) error {
+				joinErr = nil
+				if isPlayer(current, newPlayer.ID) {
+					return nil
+				}
+				// Players joining late still need problems no one else has had
+				joinErr = checkFairPlayers(current.GameConfig, max(len(current.Players), len(current.Progress))+1)
+				if joinErr != nil {
+					return joinErr
+				}
+				current.Players = append(current.Players, newPlayer)
+				return nil
+			})
+			if joinErr != nil {
+				return c.JSON(http.StatusConflict, map[string]string{"error": joinErr.Error()})
+			}
+			if gameSession == nil {
 				log.Printf("Error updating game session: %v", err)
 				return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update game session"})
 			}
@@ -195,7 +243,7 @@ func ConnectToGameSession(rdb *db.RedisClient, upgrader websocket.Upgrader) echo
 		}
 
 		// Send initial game session data
-		if err := ws.WriteJSON(gameSession); err != nil {
+		if err := ws.WriteJSON(sessionView(gameSession)); err != nil {
 			log.Printf("Error sending initial game session data: %v", err)
 			return nil
 		}
@@ -203,6 +251,9 @@ func ConnectToGameSession(rdb *db.RedisClient, upgrader websocket.Upgrader) echo
 		// Channel for WebSocket read errors
 		readErr := make(chan error)
 
+		// Channel for events the game rejected, reported back to this client only
+		eventErrs := make(chan models.SocketMessage)
+
 		// Start a goroutine to read from WebSocket
 		go func() {
 			for {
@@ -220,18 +271,34 @@ func ConnectToGameSession(rdb *db.RedisClient, upgrader websocket.Upgrader) echo
 					log.Println("Error unmarshalling message:", err)
 					continue
 				}
-				handleGameEvent(ctx, rdb, uuid.MustParse(sessionID), uuid.MustParse(userID), message.Type, message.Payload)
+				err = handleGameEvent(ctx, rdb, uuid.MustParse(sessionID), uuid.MustParse(userID), message.Type, message.Payload)
+				if err != nil {
+					select {
+					case eventErrs <- socketError(message.Type, err):
+					case <-ctx.Done():
+						return
+					}
+				}
 			}
 		}()
 
 		// Main event loop
 		for {
 			select {
-			case update := <-updates:
-				if err := ws.WriteJSON(update); err != nil {
+			case update, ok := <-updates:
+				if !ok {
+					// The session was deleted
+					return nil
+				}
+				if err := ws.WriteJSON(sessionView(update)); err != nil {
 					log.Printf("Error sending update to client: %v", err)
 					return nil
 				}
+			case msg := <-eventErrs:
+				if err := ws.WriteJSON(msg); err != nil {
+					log.Printf("Error sending error to client: %v", err)
+					return nil
+				}
 			case err := <-readErr:
 				log.Printf("WebSocket read error: %v", err)
 				return nil
@@ -242,6 +309,160 @@ func ConnectToGameSession(rdb *db.RedisClient, upgrader websocket.Upgrader) echo
 	}
 }
 
+// hiddenAnswerMethods are the methods whose answers would give a problem
+// away: the exact value of an estimate, a solution to a target puzzle, or the
+// factors of a number.
+var hiddenAnswerMethods = []models.GameConfigMethod{
+	models.GameConfigMethodEstimate,
+	models.GameConfigMethodTarget,
+	models.GameConfigMethodFactorPair,
+}
+
+// hidesAnswers reports whether a game played with config keeps its answers
+// from players until it is over.
+func hidesAnswers(config models.GameConfig) bool {
+	if config.MultipleChoice || config.Mode == models.GameModeIndividual || config.Mode == models.GameModeReview {
+		return true
+	}
+	for _, method := range hiddenAnswerMethods {
+		if slices.Contains(config.Methods, method) {
+			return true
+		}
+	}
+	return false
+}
+
+// sessionView is the game session as sent to players. In a multiple choice
+// game, an individual or review one, or one with estimates, target puzzles or
+// factor pairs, the answers to the current and upcoming problems, including
+// each player's own problems, are hidden along with the seed they could be
+// regenerated from until the game is over.
+func sessionView(gameSession *models.GameSession) *models.GameSession {
+	if gameSession == nil || gameSession.Status == models.GameSessionStatusFinished {
+		return gameSession
+	}
+	if !hidesAnswers(gameSession.GameConfig) {
+		return gameSession
+	}
+	view := *gameSession
+	view.Seed = 0
+	if view.CurrentProblemIndex < len(view.Problems) {
+		view.Problems = append(gameSession.Problems[:view.CurrentProblemIndex:view.CurrentProblemIndex],
+			game.HideAnswers(gameSession.Problems[view.CurrentProblemIndex:])...)
+	}
+	if len(gameSession.Progress) > 0 {
+		view.Progress = make([]models.PlayerProgress, len(gameSession.Progress))
+		for i, progress := range gameSession.Progress {
+			if progress.Problem != nil {
+				progress.Problem = &game.HideAnswers([]models.GameProblem{*progress.Problem})[0]
+			}
+			if progress.Answered < len(progress.Problems) {
+				progress.Problems = append(progress.Problems[:progress.Answered:progress.Answered],
+					game.HideAnswers(progress.Problems[progress.Answered:])...)
+			}
+			view.Progress[i] = progress
+		}
+	}
+	return &view
+}
+
+// addPoints adds points to a player's score, starting one if they have none yet.
+func addPoints(gameSession *models.GameSession, userID uuid.UUID, points int) {
+	var playerScore models.Score
+	var playerIndex int
+	for index, player := range gameSession.Scores {
+		if player.UserID == userID {
+			playerScore = player
+			playerIndex = index
+			break
+		}
+	}
+	if playerScore.UserID == uuid.Nil {
+		for _, player := range gameSession.Players {
+			if player.ID == userID {
+				playerScore = models.Score{
+					ID:       uuid.New(),
+					UserID:   userID,
+					Username: player.Username,
+					Points:   points,
+					IsBot:    player.IsBot,
+				}
+				break
+			}
+		}
+		gameSession.Scores = append(gameSession.Scores, playerScore)
+	} else {
+		gameSession.Scores[playerIndex].Points += points
+	}
+}
+
+// submitFixedAnswer checks a player's answer to the current problem of a
+// fixed game, moving everyone on to the next one when it is right. A wrong
+// answer is returned as a *game.Rejection. The answer is returned for the
+// player's review queue too, or nil if they already got the problem wrong,
+// as that miss is already counted.
+func submitFixedAnswer(gameSession *models.GameSession, userID uuid.UUID, submission models.Submission, now time.Time) (*reviewAnswer, error) {
+	if gameSession.Status != models.GameSessionStatusInProgress || gameSession.CurrentProblemIndex >= len(gameSession.Problems) {
+		return nil, errGameNotInProgress
+	}
+	points, err := game.ValidateSubmission(gameSession.GameConfig, gameSession.Problems[gameSession.CurrentProblemIndex], submission)
+	var rejection *game.Rejection
+	if err != nil && !(errors.As(err, &rejection) && rejection.Reason == game.RejectionIncorrect) {
+		return nil, err
+	}
+
+	answer := fixedAnswer(gameSession, userID, points > 0, now)
+	if points > 0 {
+		addPoints(gameSession, userID, points)
+		nextFixedProblem(gameSession, now)
+	} else if answer != nil {
+		gameSession.Missed = append(gameSession.Missed, userID)
+	}
+	return answer, err
+}
+
+// skipFixedProblem moves everyone in a fixed game on to the next problem,
+// counting it as missed by the player who skipped it. The miss is returned
+// for their review queue, or nil if they had already got it wrong.
+func skipFixedProblem(gameSession *models.GameSession, userID uuid.UUID, now time.Time) (*reviewAnswer, error) {
+	if gameSession.Status != models.GameSessionStatusInProgress || gameSession.CurrentProblemIndex >= len(gameSession.Problems) {
+		return nil, errGameNotInProgress
+	}
+	answer := fixedAnswer(gameSession, userID, false, now)
+	nextFixedProblem(gameSession, now)
+	return answer, nil
+}
+
+// fixedAnswer is how a player did on the current problem of a fixed game,
+// timed from when it came up, or nil if they already missed it.
+func fixedAnswer(gameSession *models.GameSession, userID uuid.UUID, correct bool, now time.Time) *reviewAnswer {
+	if slices.Contains(gameSession.Missed, userID) {
+		return nil
+	}
+	answer := reviewAnswer{
+		problem: gameSession.Problems[gameSession.CurrentProblemIndex],
+		grading: game.GradingOf(gameSession.GameConfig),
+		correct: correct,
+	}
+	// Sessions saved before problems were timed have no start to time from
+	if !gameSession.ProblemStartedAt.IsZero() {
+		answer.timeMs = int(now.Sub(gameSession.ProblemStartedAt).Milliseconds())
+	}
+	return &answer
+}
+
+// nextFixedProblem moves everyone in a fixed game on to the next problem,
+// finishing the game after the last one.
+func nextFixedProblem(gameSession *models.GameSession, now time.Time) {
+	gameSession.CurrentProblemIndex += 1
+	gameSession.ProblemStartedAt = now
+	gameSession.Missed = nil
+	if gameSession.CurrentProblemIndex >= len(gameSession.Problems) {
+		gameSession.Status = models.GameSessionStatusFinished
+		gameSession.EndTime = now
+	}
+}
+
 func removePlayerFromSession(ctx context.Context, rdb *db.RedisClient, sessionID, userID uuid.UUID) {
 	gameSession, err := rdb.GetGameSession(ctx, sessionID)
 	if err != nil {
@@ -250,152 +471,398 @@ func removePlayerFromSession(ctx context.Context, rdb *db.RedisClient, sessionID
 	}
 
 	// Remove the player from the game session
-	for i, player := range gameSession.Players {
-		if player.ID == userID {
-			gameSession.Players = append(gameSession.Players[:i], gameSession.Players[i+1:]...)
-			break
+	removePlayer := func(gameSession *models.GameSession) {
+		for i, player := range gameSession.Players {
+			if player.ID == userID {
+				gameSession.Players = append(gameSession.Players[:i], gameSession.Players[i+1:]...)
+				break
+			}
 		}
 	}
+	removePlayer(gameSession)
 
-	// If no players remain, remove the game session from active sessions
-	if len(gameSession.Players) == 0 {
+	// If no human players remain, remove the game session from active sessions.
+	// The update is still published so any bots in the session shut down.
+	if countHumanPlayers(gameSession.Players) == 0 {
 		err = rdb.UpdateActiveGameSessions(ctx, sessionID, false)
 		if err != nil {
 			log.Printf("Failed to remove empty game session from active sessions: %v", err)
 		} else {
 			log.Printf("Removed empty game session from active sessions: %s", sessionID)
 		}
+		err = rdb.PublishGameSessionUpdate(ctx, gameSession)
+		if err != nil {
+			log.Printf("Failed to publish game session update: %v", err)
+		}
 		return
 	}
 
-	// Update the game session in Redis
-	err = rdb.UpdateGameSession(ctx, gameSession)
+	// Update the game session in Redis, as it is by then, so answers the
+	// other players give meanwhile aren't lost
+	_, err = rdb.ModifyGameSession(ctx, sessionID, func(current *models.GameSession) error {
+		removePlayer(current)
+		// When everyone has their own problems, the player leaving may have
+		// been the last one still answering
+		if game.PerPlayer(current.GameConfig) && current.Status == "in_progress" {
+			finishPlayerGame(current, time.Now())
+		}
+		return nil
+	})
 	if err != nil {
 		log.Printf("Failed to update game session: %v", err)
 	}
 }
 
-func handleGameEvent(ctx context.Context, rdb *db.RedisClient, sessionID, userID uuid.UUID, eventType string, payload map[string]interface{}) {
+func handleGameEvent(ctx context.Context, rdb *db.RedisClient, sessionID, userID uuid.UUID, eventType string, payload map[string]interface{}) error {
 	gameSession, err := rdb.GetGameSession(ctx, sessionID)
 	if err != nil {
 		log.Printf("Failed to get game session: %v", err)
-		return
+		return nil
 	}
 
 	switch eventType {
 	case "start_game":
-		if gameSession.Status != "waiting" {
-			log.Printf("Game session %s is not in waiting status, cannot start game", sessionID)
-			return
-		}
-		for _, player := range gameSession.Players {
-			if player.ID == userID {
-				gameSession.Status = "in_progress"
-				err = rdb.UpdateGameSession(ctx, gameSession)
-				if err != nil {
-					log.Printf("Failed to update game session: %v", err)
-				}
-				return
+		_, err = modifySession(ctx, rdb, sessionID, func(current *models.GameSession) error {
+			if current.Status != models.GameSessionStatusWaiting {
+				log.Printf("Game session %s is not in waiting status, cannot start game", sessionID)
+				return errEventIgnored
 			}
-		}
-	case "submit_answer":
-		answerFloat, ok := payload["answer"].(float64)
-		if !ok {
-			log.Printf("Invalid answer format for session %s", sessionID)
-			return
-		}
-		answer := int(answerFloat)
-		problem := gameSession.Problems[gameSession.CurrentProblemIndex]
-		if answer == problem.Answer {
-			var playerScore models.Score
-			var playerIndex int
-			for index, player := range gameSession.Scores {
-				if player.UserID == userID {
-					playerScore = player
-					playerIndex = index
-					break
-				}
+			if !isPlayer(current, userID) {
+				return errEventIgnored
 			}
-			if playerScore.UserID == uuid.Nil {
-				for _, player := range gameSession.Players {
-					if player.ID == userID {
-						playerScore = models.Score{
-							ID:       uuid.New(),
-							UserID:   userID,
-							Username: player.Username,
-							Points:   1,
-						}
-						break
-					}
-				}
-				gameSession.Scores = append(gameSession.Scores, playerScore)
-			} else {
-				gameSession.Scores[playerIndex].Points += 1
+			if err := checkFairPlayers(current.GameConfig, len(current.Players)); err != nil {
+				log.Printf("Game session %s has too many players to start: %v", sessionID, err)
+				return err
 			}
-			err = rdb.UpdateGameSession(ctx, gameSession)
+			current.Status = models.GameSessionStatusInProgress
+			current.StartTime = time.Now()
+			current.ProblemStartedAt = current.StartTime
+			if game.PerPlayer(current.GameConfig) {
+				// Sets are dealt to the players as they are when saved
+				sets, gradings := playerSets(ctx, rdb, current, current.StartTime)
+				startProgress(current, sets, gradings, current.StartTime)
+			}
+			return nil
+		})
+		return err
+	case "submit_answer":
+		if gameSession.Status != "in_progress" {
+			log.Printf("Game session %s is not in progress, cannot submit answer", sessionID)
+			return nil
+		}
+		var submission models.Submission
+		if err := decodePayload(payload, &submission); err != nil {
+			return errors.New("invalid answer format")
+		}
+		if game.PerPlayer(gameSession.GameConfig) {
+			// Players answer at the same time, so each answer is applied to
+			// the session as it is when saved
+			var answer *reviewAnswer
+			var submitErr error
+			gameSession, err = rdb.ModifyGameSession(ctx, sessionID, func(current *models.GameSession) error {
+				answer, submitErr = nil, nil
+				if current.Status == models.GameSessionStatusInProgress {
+					answer, submitErr = submitPlayerAnswer(current, userID, submission, time.Now())
+				}
+				return nil
+			})
 			if err != nil {
 				log.Printf("Failed to update game session: %v", err)
+				return nil
+			}
+			if answer != nil {
+				recordGameAnswer(ctx, rdb, gameSession, userID, *answer)
 			}
-			gameSession.CurrentProblemIndex += 1
-			if gameSession.CurrentProblemIndex >= len(gameSession.Problems) {
-				gameSession.Status = "finished"
-				gameSession.EndTime = time.Now()
+			return submitErr
+		}
+		// Players and bots race to answer, so the answer is checked against
+		// the problem that is current when the session is saved. A first
+		// wrong answer is saved too, so the player's miss counts only once.
+		var answer *reviewAnswer
+		var submitErr error
+		gameSession, err = rdb.ModifyGameSession(ctx, sessionID, func(current *models.GameSession) error {
+			answer, submitErr = submitFixedAnswer(current, userID, submission, time.Now())
+			if answer == nil && submitErr != nil {
+				return submitErr
 			}
-			err = rdb.UpdateGameSession(ctx, gameSession)
+			return nil
+		})
+		switch {
+		case errors.Is(submitErr, errGameNotInProgress):
+			log.Printf("Game session %s is not in progress, cannot submit answer", sessionID)
+			return nil
+		case answer == nil && submitErr != nil:
+			return submitErr
+		case err != nil:
+			log.Printf("Failed to update game session: %v", err)
+			return nil
+		}
+		if answer != nil {
+			recordGameAnswer(ctx, rdb, gameSession, userID, *answer)
+		}
+		return submitErr
+	case "skip_problem":
+		if game.PerPlayer(gameSession.GameConfig) {
+			var answer *reviewAnswer
+			gameSession, err = rdb.ModifyGameSession(ctx, sessionID, func(current *models.GameSession) error {
+				answer = nil
+				if current.Status == models.GameSessionStatusInProgress {
+					answer = skipPlayerProblem(current, userID, time.Now())
+				}
+				return nil
+			})
 			if err != nil {
 				log.Printf("Failed to update game session: %v", err)
+				return nil
+			}
+			if answer != nil {
+				recordGameAnswer(ctx, rdb, gameSession, userID, *answer)
 			}
+			return nil
 		}
-	case "skip_problem":
-		gameSession.CurrentProblemIndex += 1
-		if gameSession.CurrentProblemIndex >= len(gameSession.Problems) {
-			gameSession.Status = "finished"
-			gameSession.EndTime = time.Now()
+		var answer *reviewAnswer
+		saved, err := modifySession(ctx, rdb, sessionID, func(current *models.GameSession) error {
+			var skipErr error
+			answer, skipErr = skipFixedProblem(current, userID, time.Now())
+			if errors.Is(skipErr, errGameNotInProgress) {
+				log.Printf("Game session %s is not in progress, cannot skip problem", sessionID)
+				return errEventIgnored
+			}
+			return skipErr
+		})
+		if saved != nil && answer != nil {
+			recordGameAnswer(ctx, rdb, saved, userID, *answer)
 		}
-		err = rdb.UpdateGameSession(ctx, gameSession)
+		return err
+	case "new_game":
+		newGameConfig, err := decodeGameConfig(payload["game_config"])
 		if err != nil {
-			log.Printf("Failed to update game session: %v", err)
+			log.Printf("Invalid game_config for session %s: %v", sessionID, err)
+			return err
 		}
-	case "new_game":
-		gameSession.Status = "waiting"
-		gameSession.Scores = []models.Score{}
-		gameSession.GameConfig = models.GameConfig{}
-		gameSession.Problems = []models.GameProblem{}
-		gameSession.CurrentProblemIndex = 0
-		gameConfigMap, ok := payload["game_config"].(map[string]interface{})
-		if !ok {
-			log.Printf("Invalid game_config format for session %s", sessionID)
-			return
+		seed, err := decodeSeed(payload["seed"])
+		if err != nil {
+			log.Printf("Invalid seed for session %s: %v", sessionID, err)
+			return err
 		}
+		_, err = modifySession(ctx, rdb, sessionID, func(current *models.GameSession) error {
+			if current.HostID != userID {
+				log.Printf("User %s is not the host of session %s, cannot start a new game", userID, sessionID)
+				return errors.New("only the host can start a new game")
+			}
+			if current.Status == models.GameSessionStatusInProgress {
+				// Archiving now would record a half-played round as a result
+				log.Printf("Game session %s is in progress, cannot start a new game", sessionID)
+				return errors.New("the current game must finish before a new one starts")
+			}
+			startNextRound(current, newGameConfig, seed)
+			return nil
+		})
+		return err
+	case "propose_rematch":
+		var newConfig *models.GameConfig
+		if gameConfigPayload, ok := payload["game_config"]; ok {
+			rematchConfig, err := decodeGameConfig(gameConfigPayload)
+			if err != nil {
+				log.Printf("Invalid game_config for session %s: %v", sessionID, err)
+				return err
+			}
+			newConfig = &rematchConfig
+		}
+		_, err = modifySession(ctx, rdb, sessionID, func(current *models.GameSession) error {
+			if current.HostID != userID {
+				log.Printf("User %s is not the host of session %s, cannot propose a rematch", userID, sessionID)
+				return errors.New("only the host can propose a rematch")
+			}
+			if current.Status != models.GameSessionStatusFinished {
+				log.Printf("Game session %s is not finished, cannot propose a rematch", sessionID)
+				return errors.New("a rematch can only be proposed once the game is finished")
+			}
 
-		var newGameConfig models.GameConfig
+			// Without a new config the rematch replays the current one
+			rematchConfig := current.GameConfig
+			if newConfig != nil {
+				rematchConfig = *newConfig
+			}
+			current.RematchConfig = &rematchConfig
 
-		// Convert methods
-		if methodsInterface, ok := gameConfigMap["methods"].([]interface{}); ok {
-			for _, method := range methodsInterface {
-				if methodStr, ok := method.(string); ok {
-					newGameConfig.Methods = append(newGameConfig.Methods, models.GameConfigMethod(methodStr))
+			// Votes cast for the previous proposal no longer apply
+			current.RematchVotes = []uuid.UUID{}
+			if addRematchVote(current, userID) {
+				startNextRound(current, rematchConfig, game.NewSeed())
+			}
+			return nil
+		})
+		return err
+	case "vote_rematch":
+		_, err = modifySession(ctx, rdb, sessionID, func(current *models.GameSession) error {
+			if current.Status != models.GameSessionStatusFinished {
+				log.Printf("Game session %s is not finished, cannot vote for a rematch", sessionID)
+				return errors.New("a rematch can only be voted for once the game is finished")
+			}
+			if !isPlayer(current, userID) {
+				log.Printf("User %s is not a player in session %s, cannot vote for a rematch", userID, sessionID)
+				return errors.New("only players can vote for a rematch")
+			}
+			if addRematchVote(current, userID) {
+				rematchConfig := current.GameConfig
+				if current.RematchConfig != nil {
+					rematchConfig = *current.RematchConfig
 				}
+				startNextRound(current, rematchConfig, game.NewSeed())
 			}
-		}
-
-		// Convert range
-		if rangeMap, ok := gameConfigMap["range"].(map[string]interface{}); ok {
-			if min, ok := rangeMap["min"].(float64); ok {
-				newGameConfig.Range.Min = int(min)
+			return nil
+		})
+		return err
+	case "add_bot":
+		var bot *models.Bot
+		saved, err := modifySession(ctx, rdb, sessionID, func(current *models.GameSession) error {
+			if current.HostID != userID {
+				log.Printf("User %s is not the host of session %s, cannot add bot", userID, sessionID)
+				return errEventIgnored
+			}
+			if err := checkFairPlayers(current.GameConfig, max(len(current.Players), len(current.Progress))+1); err != nil {
+				log.Printf("Session %s has no room for a bot: %v", sessionID, err)
+				return err
 			}
-			if max, ok := rangeMap["max"].(float64); ok {
-				newGameConfig.Range.Max = int(max)
+			var err error
+			bot, err = newBot(current, payload)
+			if err != nil {
+				log.Printf("Invalid bot for session %s: %v", sessionID, err)
+				return err
 			}
+			current.Bots = append(current.Bots, *bot)
+			current.Players = append(current.Players, models.User{
+				ID:       bot.ID,
+				Username: bot.Username,
+				IsBot:    true,
+			})
+			return nil
+		})
+		if saved != nil {
+			go runBot(rdb, sessionID, *bot)
 		}
-
-		gameSession.GameConfig = newGameConfig
-		gameSession.Problems = game.GenerateGameProblems(newGameConfig)
-		err = rdb.UpdateGameSession(ctx, gameSession)
+		return err
+	case "remove_bot":
+		botIDStr, ok := payload["bot_id"].(string)
+		if !ok {
+			log.Printf("Invalid bot_id format for session %s", sessionID)
+			return nil
+		}
+		botID, err := uuid.Parse(botIDStr)
 		if err != nil {
-			log.Printf("Failed to update game session: %v", err)
+			log.Printf("Invalid bot_id for session %s: %v", sessionID, err)
+			return nil
 		}
+		_, err = modifySession(ctx, rdb, sessionID, func(current *models.GameSession) error {
+			if current.HostID != userID {
+				log.Printf("User %s is not the host of session %s, cannot remove bot", userID, sessionID)
+				return errEventIgnored
+			}
+			for i, bot := range current.Bots {
+				if bot.ID == botID {
+					current.Bots = append(current.Bots[:i], current.Bots[i+1:]...)
+					break
+				}
+			}
+			for i, player := range current.Players {
+				if player.ID == botID {
+					current.Players = append(current.Players[:i], current.Players[i+1:]...)
+					break
+				}
+			}
+			return nil
+		})
+		return err
+	}
+
+	return nil
+}
 
+// errEventIgnored aborts a change to the session that the session is no
+// longer in a state to take, without reporting an error back to the player.
+var errEventIgnored = errors.New("event ignored")
+
+// modifySession applies an event's change to the session as it is when
+// saved, so changes other players make meanwhile aren't lost. It returns the
+// saved session, or nil if the change was aborted or couldn't be saved. An
+// error modify aborts with, other than errEventIgnored, is returned to be
+// reported back to the player; failing to save is only logged.
+func modifySession(ctx context.Context, rdb *db.RedisClient, sessionID uuid.UUID, modify func(*models.GameSession) error) (*models.GameSession, error) {
+	var eventErr error
+	gameSession, err := rdb.ModifyGameSession(ctx, sessionID, func(current *models.GameSession) error {
+		eventErr = modify(current)
+		return eventErr
+	})
+	switch {
+	case errors.Is(eventErr, errEventIgnored):
+		return nil, nil
+	case eventErr != nil:
+		return nil, eventErr
+	case err != nil:
+		log.Printf("Failed to update game session: %v", err)
+	}
+	return gameSession, nil
+}
+
+// decodePayload converts a loosely typed socket payload value into out.
+func decodePayload(value interface{}, out interface{}) error {
+	valueJSON, err := json.Marshal(value)
+	if err != nil {
+		return err
 	}
+	return json.Unmarshal(valueJSON, out)
+}
 
+func decodeGameConfig(value interface{}) (models.GameConfig, error) {
+	var gameConfig models.GameConfig
+	if value == nil {
+		return gameConfig, errors.New("game_config is required")
+	}
+	if err := decodePayload(value, &gameConfig); err != nil {
+		return gameConfig, err
+	}
+	return gameConfig, game.ValidateGameConfig(gameConfig)
+}
+
+// decodeSeed reads an optional seed from a socket payload, picking a fresh one when absent.
+func decodeSeed(value interface{}) (int64, error) {
+	if value == nil {
+		return game.NewSeed(), nil
+	}
+	seed, ok := value.(float64)
+	if !ok || seed < 0 || seed > game.MaxSeed || seed != float64(int64(seed)) {
+		return 0, errors.New("seed must be a whole number between 0 and 2^53-1")
+	}
+	return int64(seed), nil
+}
+
+func configErrorResponse(err error) map[string]interface{} {
+	response := map[string]interface{}{"error": "Invalid game config"}
+	var validationErrs game.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		response["fields"] = validationErrs
+	}
+	return response
+}
+
+// socketError builds the message sent back to a client whose event was rejected.
+func socketError(eventType string, err error) models.SocketMessage {
+	payload := map[string]interface{}{
+		"event": eventType,
+		"error": err.Error(),
+	}
+	var validationErrs game.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		payload = configErrorResponse(err)
+		payload["event"] = eventType
+	}
+	var rejection *game.Rejection
+	if errors.As(err, &rejection) {
+		payload["reason"] = rejection.Reason
+		if rejection.Field != "" {
+			payload["field"] = rejection.Field
+		}
+	}
+	return models.SocketMessage{Type: "error", Payload: payload}
 }
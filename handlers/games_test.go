@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/FiveEightyEight/mwfapi/game"
+	"github.com/FiveEightyEight/mwfapi/models"
+	"github.com/google/uuid"
+)
+
+func TestSessionView(t *testing.T) {
+	config := models.GameConfig{
+		Methods: []models.GameConfigMethod{models.GameConfigMethodEstimate},
+		Range:   models.GameConfigRange{Min: 10, Max: 99},
+	}
+	gameSession := &models.GameSession{
+		GameConfig:          config,
+		Seed:                5,
+		Problems:            game.GenerateGameProblems(config, 5),
+		CurrentProblemIndex: 1,
+		Status:              models.GameSessionStatusInProgress,
+	}
+
+	view := sessionView(gameSession)
+	if view.Seed != 0 {
+		t.Error("expected the seed to be hidden")
+	}
+	if view.Problems[0].Answer.IsZero() {
+		t.Error("expected the answer to a problem already played to be shown")
+	}
+	for _, problem := range view.Problems[1:] {
+		if !problem.Answer.IsZero() {
+			t.Fatalf("expected the exact answer to %s to be hidden, got %s", problem.Text, problem.Answer)
+		}
+	}
+	if gameSession.Problems[1].Answer.IsZero() {
+		t.Error("expected the session itself to keep its answers")
+	}
+
+	gameSession.Status = models.GameSessionStatusFinished
+	if view := sessionView(gameSession); view.Problems[1].Answer.IsZero() {
+		t.Error("expected the answers to be shown once the game is over")
+	}
+
+	gameSession.Status = models.GameSessionStatusInProgress
+	gameSession.GameConfig.Methods = []models.GameConfigMethod{models.GameConfigMethodAdd}
+	if view := sessionView(gameSession); view.Seed == 0 {
+		t.Error("expected a game of plain sums to be sent as it is")
+	}
+}
+
+func TestFixedAnswers(t *testing.T) {
+	alice := models.User{ID: uuid.New(), Username: "alice"}
+	bob := models.User{ID: uuid.New(), Username: "bob"}
+	config := models.GameConfig{
+		Methods:      []models.GameConfigMethod{models.GameConfigMethodAdd},
+		Range:        models.GameConfigRange{Min: 1, Max: 20},
+		ProblemCount: 3,
+	}
+	start := time.Now()
+	gameSession := &models.GameSession{
+		GameConfig:       config,
+		Problems:         game.GenerateGameProblems(config, 3),
+		Players:          []models.User{alice, bob},
+		Status:           models.GameSessionStatusInProgress,
+		ProblemStartedAt: start,
+	}
+	answer := func(index, off int) models.Submission {
+		return models.Submission{Answer: models.IntegerValue(gameSession.Problems[index].Answer.Int() + off)}
+	}
+
+	// Only the first wrong answer to a problem counts as a miss
+	missed, err := submitFixedAnswer(gameSession, alice.ID, answer(0, 1), start.Add(time.Second))
+	if err == nil || missed == nil || missed.correct {
+		t.Fatalf("expected a miss for a wrong answer, got %+v and %v", missed, err)
+	}
+	if again, err := submitFixedAnswer(gameSession, alice.ID, answer(0, 2), start.Add(2*time.Second)); err == nil || again != nil {
+		t.Fatalf("expected a second wrong answer not to count again, got %+v and %v", again, err)
+	}
+	if late, _ := submitFixedAnswer(gameSession, alice.ID, answer(0, 0), start.Add(3*time.Second)); late != nil || gameSession.CurrentProblemIndex != 1 {
+		t.Fatalf("expected a right answer after a miss to move on without counting, got %+v", late)
+	}
+
+	// A right answer is timed from when the problem came up
+	right, err := submitFixedAnswer(gameSession, bob.ID, answer(1, 0), start.Add(45*time.Second))
+	if err != nil || right == nil || !right.correct || right.timeMs != 42000 {
+		t.Fatalf("expected a right answer taking 42s, got %+v and %v", right, err)
+	}
+	if len(gameSession.Missed) != 0 || !gameSession.ProblemStartedAt.Equal(start.Add(45*time.Second)) {
+		t.Error("expected the next problem to start afresh")
+	}
+
+	// Skipping counts as a miss, and the last problem ends the game
+	skipped, err := skipFixedProblem(gameSession, bob.ID, start.Add(50*time.Second))
+	if err != nil || skipped == nil || skipped.correct || !reflect.DeepEqual(skipped.problem, gameSession.Problems[2]) {
+		t.Fatalf("expected a skip to count as a miss of the last problem, got %+v and %v", skipped, err)
+	}
+	if gameSession.Status != models.GameSessionStatusFinished {
+		t.Errorf("expected the game to finish, got %s", gameSession.Status)
+	}
+	if _, err := skipFixedProblem(gameSession, bob.ID, start.Add(time.Minute)); err != errGameNotInProgress {
+		t.Errorf("expected no skipping once the game is over, got %v", err)
+	}
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/FiveEightyEight/mwfapi/db"
+	"github.com/FiveEightyEight/mwfapi/game"
+	"github.com/FiveEightyEight/mwfapi/models"
+	"github.com/google/uuid"
+)
+
+// roundProblems is the problem set shared by every player in a round. Games
+// that give each player their own problems have none.
+func roundProblems(config models.GameConfig, seed int64) []models.GameProblem {
+	if game.PerPlayer(config) {
+		return []models.GameProblem{}
+	}
+	return game.GenerateGameProblems(config, seed)
+}
+
+// playerProgress returns a player's progress through their own problems,
+// starting them off if they joined after the game began.
+func playerProgress(gameSession *models.GameSession, userID uuid.UUID, now time.Time) *models.PlayerProgress {
+	for i := range gameSession.Progress {
+		if gameSession.Progress[i].UserID == userID {
+			return &gameSession.Progress[i]
+		}
+	}
+	if !isPlayer(gameSession, userID) {
+		return nil
+	}
+
+	// A review game's sets are drawn when it starts, so latecomers have none
+	var problems []models.GameProblem
+	if gameSession.GameConfig.Mode == models.GameModeIndividual {
+		// The newcomer's set is matched to the sets already dealt out
+		sets := make([][]models.GameProblem, len(gameSession.Progress))
+		for i, progress := range gameSession.Progress {
+			sets[i] = progress.Problems
+		}
+		problems = game.NextFairProblemSet(gameSession.GameConfig, gameSession.Seed, sets)
+	}
+	gameSession.Progress = append(gameSession.Progress, game.StartProgress(gameSession.GameConfig, gameSession.Seed, userID, problems, now))
+	return &gameSession.Progress[len(gameSession.Progress)-1]
+}
+
+// checkFairPlayers reports an error if an individual game would have more
+// players than it has problems to give each their own set. players counts
+// those who have held a set too, as their problems stay dealt out.
+func checkFairPlayers(config models.GameConfig, players int) error {
+	if config.Mode != models.GameModeIndividual {
+		return nil
+	}
+	if limit, ok := game.MaxFairPlayers(config); ok && players > limit {
+		return fmt.Errorf("the game has only enough different problems for %d players", limit)
+	}
+	return nil
+}
+
+// playerSets gives each player their own problem set in an individual or
+// review game, and in a review game how each problem is graded. Adaptive
+// games make up problems as they go, so have none.
+func playerSets(ctx context.Context, rdb *db.RedisClient, gameSession *models.GameSession, now time.Time) ([][]models.GameProblem, [][]models.ReviewGrading) {
+	config := gameSession.GameConfig
+	switch config.Mode {
+	case models.GameModeIndividual:
+		return game.GenerateFairProblemSets(config, gameSession.Seed, len(gameSession.Players)), nil
+	case models.GameModeReview:
+		return reviewSets(ctx, rdb, gameSession, now)
+	}
+	return nil, nil
+}
+
+// startProgress puts every player on the first problem of their set in sets,
+// or of their own making when sets is nil. gradings, if not nil, says how
+// each player's problems are graded.
+func startProgress(gameSession *models.GameSession, sets [][]models.GameProblem, gradings [][]models.ReviewGrading, now time.Time) {
+	gameSession.Progress = make([]models.PlayerProgress, len(gameSession.Players))
+	for i, player := range gameSession.Players {
+		var problems []models.GameProblem
+		if sets != nil {
+			problems = sets[i]
+		}
+		gameSession.Progress[i] = game.StartProgress(gameSession.GameConfig, gameSession.Seed, player.ID, problems, now)
+		if gradings != nil {
+			gameSession.Progress[i].Gradings = gradings[i]
+		}
+	}
+	// A review game where nobody has anything due is over before it starts
+	finishPlayerGame(gameSession, now)
+}
+
+// submitPlayerAnswer checks a player's answer to their own problem and
+// moves them on to the next one, right or wrong. Malformed submissions are
+// rejected without using up the problem. The answer is returned for the
+// player's review queue, or nil if it was rejected.
+func submitPlayerAnswer(gameSession *models.GameSession, userID uuid.UUID, submission models.Submission, now time.Time) (*reviewAnswer, error) {
+	progress := playerProgress(gameSession, userID, now)
+	if progress == nil || progress.Problem == nil {
+		return nil, errors.New("no problem left to answer")
+	}
+
+	points, err := game.ValidateSubmission(problemConfig(gameSession, progress), *progress.Problem, submission)
+	var rejection *game.Rejection
+	if err != nil && !(errors.As(err, &rejection) && rejection.Reason == game.RejectionIncorrect) {
+		return nil, err
+	}
+	if points > 0 {
+		addPoints(gameSession, userID, points)
+	}
+	answer := advancePlayer(gameSession, progress, points > 0, now)
+	return &answer, err
+}
+
+// skipPlayerProblem moves a player past their problem, counting it as
+// missed. The miss is returned for the player's review queue, or nil if
+// they had no problem to skip.
+func skipPlayerProblem(gameSession *models.GameSession, userID uuid.UUID, now time.Time) *reviewAnswer {
+	progress := playerProgress(gameSession, userID, now)
+	if progress == nil || progress.Problem == nil {
+		return nil
+	}
+	answer := advancePlayer(gameSession, progress, false, now)
+	return &answer
+}
+
+// problemConfig is the config a player's current problem is graded with:
+// the game's, with the grading of the fact under review in a review game.
+func problemConfig(gameSession *models.GameSession, progress *models.PlayerProgress) models.GameConfig {
+	if progress.Answered < len(progress.Gradings) {
+		return game.GradingConfig(gameSession.GameConfig, progress.Gradings[progress.Answered])
+	}
+	return gameSession.GameConfig
+}
+
+// advancePlayer moves a player on from their current problem, returning how
+// they did on it.
+func advancePlayer(gameSession *models.GameSession, progress *models.PlayerProgress, correct bool, now time.Time) reviewAnswer {
+	answer := reviewAnswer{
+		problem: *progress.Problem,
+		grading: game.GradingOf(problemConfig(gameSession, progress)),
+		correct: correct,
+		timeMs:  int(now.Sub(progress.StartedAt).Milliseconds()),
+	}
+	game.AdvanceProgress(gameSession.GameConfig, gameSession.Seed, progress, correct, now)
+	finishPlayerGame(gameSession, now)
+	return answer
+}
+
+// finishPlayerGame ends the game once every player still in it has
+// answered all their problems.
+func finishPlayerGame(gameSession *models.GameSession, now time.Time) {
+	for _, player := range gameSession.Players {
+		if progress := playerProgress(gameSession, player.ID, now); progress.Problem != nil {
+			return
+		}
+	}
+	gameSession.Status = models.GameSessionStatusFinished
+	gameSession.EndTime = now
+}
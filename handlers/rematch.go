@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/FiveEightyEight/mwfapi/db"
+	"github.com/FiveEightyEight/mwfapi/models"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+func GetGameSessionHistory(rdb *db.RedisClient) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		sessionID, err := uuid.Parse(c.Param("game_session_id"))
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid game session ID"})
+		}
+
+		gameSession, err := rdb.GetGameSession(c.Request().Context(), sessionID)
+		if err != nil {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Game session not found"})
+		}
+
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"round":     gameSession.Round,
+			"history":   gameSession.History,
+			"standings": gameSession.Standings,
+		})
+	}
+}
+
+// archiveRound moves the current round's results into the session history
+// and folds them into the cumulative standings, reporting whether it did.
+// Only finished rounds count.
+func archiveRound(gameSession *models.GameSession) bool {
+	if gameSession.Status != models.GameSessionStatusFinished {
+		return false
+	}
+
+	gameSession.History = append(gameSession.History, models.GameRound{
+		Number:     gameSession.Round,
+		GameConfig: gameSession.GameConfig,
+		Seed:       gameSession.Seed,
+		Scores:     gameSession.Scores,
+		StartTime:  gameSession.StartTime,
+		EndTime:    gameSession.EndTime,
+	})
+
+	var topPoints int
+	for _, score := range gameSession.Scores {
+		if score.Points > topPoints {
+			topPoints = score.Points
+		}
+	}
+
+	for _, score := range gameSession.Scores {
+		index := -1
+		for i, standing := range gameSession.Standings {
+			if standing.UserID == score.UserID {
+				index = i
+				break
+			}
+		}
+		if index == -1 {
+			gameSession.Standings = append(gameSession.Standings, models.Standing{
+				UserID:   score.UserID,
+				Username: score.Username,
+				IsBot:    score.IsBot,
+			})
+			index = len(gameSession.Standings) - 1
+		}
+		gameSession.Standings[index].Points += score.Points
+		if topPoints > 0 && score.Points == topPoints {
+			gameSession.Standings[index].RoundsWon += 1
+		}
+	}
+	return true
+}
+
+// startNextRound archives the current round and resets the session for a
+// fresh one with the same roster, generating its problems from seed. A round
+// that was never played is replaced rather than counted.
+func startNextRound(gameSession *models.GameSession, config models.GameConfig, seed int64) {
+	if archiveRound(gameSession) {
+		gameSession.Round += 1
+	}
+	gameSession.Status = models.GameSessionStatusWaiting
+	gameSession.Scores = []models.Score{}
+	gameSession.GameConfig = config
+	gameSession.Seed = seed
+	gameSession.Problems = roundProblems(config, seed)
+	gameSession.CurrentProblemIndex = 0
+	gameSession.ProblemStartedAt = time.Time{}
+	gameSession.Missed = nil
+	gameSession.Progress = nil
+	gameSession.StartTime = time.Time{}
+	gameSession.EndTime = time.Time{}
+	gameSession.RematchVotes = []uuid.UUID{}
+	gameSession.RematchConfig = nil
+}
+
+// addRematchVote records a human player's vote and reports whether every
+// human player still in the session has now voted.
+func addRematchVote(gameSession *models.GameSession, userID uuid.UUID) bool {
+	voted := false
+	for _, vote := range gameSession.RematchVotes {
+		if vote == userID {
+			voted = true
+			break
+		}
+	}
+	if !voted {
+		gameSession.RematchVotes = append(gameSession.RematchVotes, userID)
+	}
+
+	for _, player := range gameSession.Players {
+		if player.IsBot {
+			continue
+		}
+		found := false
+		for _, vote := range gameSession.RematchVotes {
+			if vote == player.ID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
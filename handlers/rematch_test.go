@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/FiveEightyEight/mwfapi/models"
+	"github.com/google/uuid"
+)
+
+func TestStartNextRound(t *testing.T) {
+	alice := models.User{ID: uuid.New(), Username: "alice"}
+	bob := models.User{ID: uuid.New(), Username: "bob"}
+	config := models.GameConfig{
+		Methods: []models.GameConfigMethod{models.GameConfigMethodAdd},
+		Range:   models.GameConfigRange{Min: 1, Max: 20},
+	}
+	gameSession := &models.GameSession{
+		Players: []models.User{alice, bob},
+		Status:  models.GameSessionStatusWaiting,
+		Round:   1,
+	}
+
+	// Replacing a round nobody played neither archives nor counts it
+	startNextRound(gameSession, config, 1)
+	if gameSession.Round != 1 || len(gameSession.History) != 0 {
+		t.Fatalf("expected round 1 with no history, got round %d with %d archived", gameSession.Round, len(gameSession.History))
+	}
+
+	gameSession.Status = models.GameSessionStatusFinished
+	gameSession.Scores = []models.Score{
+		{UserID: alice.ID, Username: alice.Username, Points: 5},
+		{UserID: bob.ID, Username: bob.Username, Points: 3},
+	}
+	startNextRound(gameSession, config, 2)
+	if gameSession.Round != 2 || len(gameSession.History) != 1 || gameSession.History[0].Number != 1 {
+		t.Fatalf("expected round 1 archived and round 2 next, got round %d with history %+v", gameSession.Round, gameSession.History)
+	}
+	if gameSession.Status != models.GameSessionStatusWaiting || len(gameSession.Scores) != 0 || gameSession.Seed != 2 {
+		t.Fatalf("expected a fresh waiting round on seed 2, got %+v", gameSession)
+	}
+
+	gameSession.Status = models.GameSessionStatusFinished
+	gameSession.Scores = []models.Score{
+		{UserID: alice.ID, Username: alice.Username, Points: 2},
+		{UserID: bob.ID, Username: bob.Username, Points: 4},
+	}
+	startNextRound(gameSession, config, 3)
+	if gameSession.Round != 3 || gameSession.History[1].Number != 2 {
+		t.Fatalf("expected round 2 archived and round 3 next, got round %d with history %+v", gameSession.Round, gameSession.History)
+	}
+	for _, standing := range gameSession.Standings {
+		if standing.Points != 7 || standing.RoundsWon != 1 {
+			t.Errorf("expected %s to have 7 points over 1 round won, got %+v", standing.Username, standing)
+		}
+	}
+}
+
+func TestAddRematchVote(t *testing.T) {
+	alice := models.User{ID: uuid.New(), Username: "alice"}
+	bob := models.User{ID: uuid.New(), Username: "bob"}
+	bot := models.User{ID: uuid.New(), Username: "bot", IsBot: true}
+	gameSession := &models.GameSession{Players: []models.User{alice, bot, bob}}
+
+	if addRematchVote(gameSession, alice.ID) {
+		t.Fatal("expected bob's vote to still be missing")
+	}
+	if addRematchVote(gameSession, alice.ID) || len(gameSession.RematchVotes) != 1 {
+		t.Fatalf("expected a repeated vote to count once, got %v", gameSession.RematchVotes)
+	}
+	if !addRematchVote(gameSession, bob.ID) {
+		t.Fatal("expected every human player to have voted, bots aside")
+	}
+}
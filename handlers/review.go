@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/FiveEightyEight/mwfapi/db"
+	"github.com/FiveEightyEight/mwfapi/game"
+	"github.com/FiveEightyEight/mwfapi/models"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// reviewAnswer is an answer a user gave to one problem, as it counts for
+// their review queue, and how it was graded. A timeMs of zero means the time
+// is unknown.
+type reviewAnswer struct {
+	problem models.GameProblem
+	grading models.ReviewGrading
+	correct bool
+	timeMs  int
+}
+
+func GetReviewQueue(rdb *db.RedisClient) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		userID := uuid.MustParse(c.Get("userID").(string))
+
+		items, err := rdb.GetReviewQueue(c.Request().Context(), userID)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve review queue"})
+		}
+
+		game.SortReviews(items)
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"items": items,
+			"due":   len(game.DueReviews(items, time.Now(), len(items))),
+		})
+	}
+}
+
+func ResetReviewQueue(rdb *db.RedisClient) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		userID := uuid.MustParse(c.Get("userID").(string))
+
+		err := rdb.ResetReviewQueue(c.Request().Context(), userID)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to reset review queue"})
+		}
+
+		return c.JSON(http.StatusOK, map[string]string{"message": "Review queue reset successfully"})
+	}
+}
+
+// reviewSets builds each player's problems in a review game from the facts
+// due in their review queue, along with how each is graded. Bots have no
+// queue, so get no problems.
+func reviewSets(ctx context.Context, rdb *db.RedisClient, gameSession *models.GameSession, now time.Time) ([][]models.GameProblem, [][]models.ReviewGrading) {
+	sets := make([][]models.GameProblem, len(gameSession.Players))
+	gradings := make([][]models.ReviewGrading, len(gameSession.Players))
+	for i, player := range gameSession.Players {
+		if player.IsBot {
+			continue
+		}
+		items, err := rdb.GetReviewQueue(ctx, player.ID)
+		if err != nil {
+			log.Printf("Failed to get review queue for user %s: %v", player.ID, err)
+			continue
+		}
+		sets[i], gradings[i] = game.ReviewProblems(gameSession.GameConfig, gameSession.Seed+int64(i), game.DueReviews(items, now, len(items)))
+	}
+	return sets, gradings
+}
+
+// recordGameAnswer feeds a player's answer in a game session into their
+// review queue. Bots' answers are left out.
+func recordGameAnswer(ctx context.Context, rdb *db.RedisClient, gameSession *models.GameSession, userID uuid.UUID, answer reviewAnswer) {
+	for _, player := range gameSession.Players {
+		if player.ID == userID && !player.IsBot {
+			updateReviewQueue(ctx, rdb, userID, gameSession.GameConfig.Mode == models.GameModeReview, []reviewAnswer{answer})
+			return
+		}
+	}
+}
+
+// recordAttemptAnswers feeds the graded answers of a daily or challenge
+// attempt, graded with config, into the user's review queue, and keeps them as samples to
+// calibrate problem difficulty on.
+func recordAttemptAnswers(ctx context.Context, rdb *db.RedisClient, userID uuid.UUID, config models.GameConfig, problems []models.GameProblem, answers []models.AttemptAnswer) {
+	grading := game.GradingOf(config)
+	reviewAnswers := make([]reviewAnswer, len(answers))
+	for i, answer := range answers {
+		reviewAnswers[i] = reviewAnswer{problem: problems[i], grading: grading, correct: answer.Correct, timeMs: answer.TimeMs}
+	}
+	updateReviewQueue(ctx, rdb, userID, false, reviewAnswers)
+
+	if err := rdb.AddDifficultySamples(ctx, game.DifficultySamples(problems, answers)...); err != nil {
+		log.Printf("Failed to record difficulty samples for user %s: %v", userID, err)
+	}
+}
+
+// updateReviewQueue sends the facts the user got wrong or was slow on to box
+// 1 of their review queue. When reviewing, the answers also move the facts
+// already queued on, a quick right answer taking them up a box. Failures are
+// only logged, as the answers themselves have already counted.
+func updateReviewQueue(ctx context.Context, rdb *db.RedisClient, userID uuid.UUID, reviewing bool, answers []reviewAnswer) {
+	items, err := rdb.GetReviewQueue(ctx, userID)
+	if err != nil {
+		log.Printf("Failed to get review queue for user %s: %v", userID, err)
+		return
+	}
+	queue := map[string]models.ReviewItem{}
+	for _, item := range items {
+		queue[item.Key] = item
+	}
+
+	now := time.Now()
+	changed := map[string]bool{}
+	removed := []string{}
+	for _, answer := range answers {
+		key := game.ReviewKey(answer.problem)
+		item, queued := queue[key]
+		missed := game.NeedsReview(answer.problem, answer.correct, answer.timeMs)
+		switch {
+		case reviewing && queued:
+			next, done := game.RecordReview(item, !missed, now)
+			if done {
+				delete(queue, key)
+				delete(changed, key)
+				removed = append(removed, key)
+				continue
+			}
+			queue[key] = next
+		case missed && queued:
+			queue[key] = game.RecordMiss(&item, answer.problem, answer.grading, now)
+		case missed:
+			queue[key] = game.RecordMiss(nil, answer.problem, answer.grading, now)
+		default:
+			continue
+		}
+		changed[key] = true
+	}
+
+	items = make([]models.ReviewItem, 0, len(queue))
+	for _, item := range queue {
+		items = append(items, item)
+	}
+	overflow := game.OverflowReviews(items)
+	for _, key := range overflow {
+		delete(changed, key)
+	}
+	saved := []models.ReviewItem{}
+	for key := range changed {
+		saved = append(saved, queue[key])
+	}
+
+	// Remove first, so a fact reviewed off the queue and missed again stays
+	if err := rdb.RemoveReviewItems(ctx, userID, append(removed, overflow...)...); err != nil {
+		log.Printf("Failed to trim review queue for user %s: %v", userID, err)
+	}
+	if err := rdb.SaveReviewItems(ctx, userID, saved...); err != nil {
+		log.Printf("Failed to update review queue for user %s: %v", userID, err)
+	}
+}
@@ -9,13 +9,15 @@ import (
 type User struct {
 	ID       uuid.UUID `json:"id"`
 	Username string    `json:"username"`
+	IsBot    bool      `json:"is_bot,omitempty"`
 }
 
 type Score struct {
 	ID       uuid.UUID `json:"id"`
 	UserID   uuid.UUID `json:"user_id"`
 	Username string    `json:"username"`
-	Points   int8      `json:"points"`
+	Points   int       `json:"points"`
+	IsBot    bool      `json:"is_bot,omitempty"`
 }
 
 type Game struct {
@@ -33,18 +35,102 @@ const (
 	GameSessionStatusFinished   GameSessionStatus = "finished"
 )
 
+// GameSession is a game being played. In a fixed game ProblemStartedAt is
+// when the current problem came up and Missed lists the players who have
+// already got it wrong, so each player's miss is only counted once.
 type GameSession struct {
 	ID                  uuid.UUID         `json:"id"`
 	Name                string            `json:"name"`
 	GameID              uuid.UUID         `json:"game_id"`
+	HostID              uuid.UUID         `json:"host_id"`
 	GameConfig          GameConfig        `json:"game_config"`
+	Seed                int64             `json:"seed"`
 	Problems            []GameProblem     `json:"problems"`
 	CurrentProblemIndex int               `json:"current_problem_index"`
+	ProblemStartedAt    time.Time         `json:"problem_started_at"`
+	Missed              []uuid.UUID       `json:"missed,omitempty"`
 	StartTime           time.Time         `json:"start_time"`
 	EndTime             time.Time         `json:"end_time"`
 	Players             []User            `json:"players"`
+	Bots                []Bot             `json:"bots"`
 	Scores              []Score           `json:"scores"`
 	Status              GameSessionStatus `json:"status"`
+	Round               int               `json:"round"`
+	History             []GameRound       `json:"history"`
+	Standings           []Standing        `json:"standings"`
+	RematchVotes        []uuid.UUID       `json:"rematch_votes"`
+	RematchConfig       *GameConfig       `json:"rematch_config,omitempty"`
+	Progress            []PlayerProgress  `json:"progress,omitempty"`
+}
+
+// PlayerProgress is one player's way through an adaptive or individual
+// game, where each player is given their own problems. Problem is the one
+// they are on, nil once they have answered them all. In an individual game
+// Problems is the player's whole set and Difficulty its total difficulty,
+// in an adaptive one Level is the level they are playing at and Asked the
+// ReviewKey of each problem answered so far, so none comes up twice. In a
+// review game Gradings says how each of Problems is graded, in the same order.
+type PlayerProgress struct {
+	UserID     uuid.UUID       `json:"user_id"`
+	Level      int             `json:"level,omitempty"`
+	Problem    *GameProblem    `json:"problem,omitempty"`
+	Problems   []GameProblem   `json:"problems,omitempty"`
+	Gradings   []ReviewGrading `json:"gradings,omitempty"`
+	Asked      []string        `json:"asked,omitempty"`
+	Difficulty float64         `json:"difficulty,omitempty"`
+	Answered   int             `json:"answered"`
+	Recent     []ProblemResult `json:"recent"`
+	StartedAt  time.Time       `json:"started_at"`
+}
+
+// ProblemResult is how a player did on one problem.
+type ProblemResult struct {
+	Correct bool `json:"correct"`
+	TimeMs  int  `json:"time_ms"`
+}
+
+// ReviewItem is a fact a user got wrong or was slow on, waiting in their
+// review queue. It sits in one of the Leitner boxes, starting in box 1, and
+// comes back for review once DueAt has passed. Each right answer in a review
+// moves it up a box, to be seen less often, and each miss sends it back to
+// box 1. Grading is how answers were graded in the game it was last missed
+// in, so reviews grade it the same way.
+type ReviewItem struct {
+	Key       string        `json:"key"`
+	Problem   GameProblem   `json:"problem"`
+	Grading   ReviewGrading `json:"grading"`
+	Box       int           `json:"box"`
+	DueAt     time.Time     `json:"due_at"`
+	Misses    int           `json:"misses"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+// ReviewGrading holds the settings of a game config that decide whether an
+// answer is right and what it earns.
+type ReviewGrading struct {
+	RequireSimplestForm bool                  `json:"require_simplest_form,omitempty"`
+	Tolerance           *Value                `json:"tolerance,omitempty"`
+	EstimationBands     []GameConfigErrorBand `json:"estimation_bands,omitempty"`
+	TargetBands         []GameConfigErrorBand `json:"target_bands,omitempty"`
+}
+
+// GameRound is the archived result of one finished round of a game session.
+type GameRound struct {
+	Number     int        `json:"number"`
+	GameConfig GameConfig `json:"game_config"`
+	Seed       int64      `json:"seed"`
+	Scores     []Score    `json:"scores"`
+	StartTime  time.Time  `json:"start_time"`
+	EndTime    time.Time  `json:"end_time"`
+}
+
+// Standing is a player's cumulative result across every round of a game session.
+type Standing struct {
+	UserID    uuid.UUID `json:"user_id"`
+	Username  string    `json:"username"`
+	Points    int       `json:"points"`
+	RoundsWon int       `json:"rounds_won"`
+	IsBot     bool      `json:"is_bot,omitempty"`
 }
 
 type ActiveGameSession struct {
@@ -63,18 +149,374 @@ const (
 	GameConfigMethodSubtract GameConfigMethod = "subtract"
 	GameConfigMethodMultiply GameConfigMethod = "multiply"
 	GameConfigMethodDivide   GameConfigMethod = "divide"
+
+	GameConfigMethodExpression GameConfigMethod = "expression"
+	GameConfigMethodFraction   GameConfigMethod = "fraction"
+
+	GameConfigMethodDecimal      GameConfigMethod = "decimal"
+	GameConfigMethodPercentOf    GameConfigMethod = "percent_of"
+	GameConfigMethodPercentRatio GameConfigMethod = "percent_ratio"
+
+	GameConfigMethodPower      GameConfigMethod = "power"
+	GameConfigMethodSquare     GameConfigMethod = "square"
+	GameConfigMethodCube       GameConfigMethod = "cube"
+	GameConfigMethodSquareRoot GameConfigMethod = "square_root"
+	GameConfigMethodPowerOfTen GameConfigMethod = "power_of_ten"
+
+	GameConfigMethodGCD          GameConfigMethod = "gcd"
+	GameConfigMethodLCM          GameConfigMethod = "lcm"
+	GameConfigMethodIsPrime      GameConfigMethod = "is_prime"
+	GameConfigMethodPrimeFactors GameConfigMethod = "prime_factors"
+
+	GameConfigMethodSequence GameConfigMethod = "sequence"
+	GameConfigMethodCompare  GameConfigMethod = "compare"
+	GameConfigMethodEstimate GameConfigMethod = "estimate"
+	GameConfigMethodTarget   GameConfigMethod = "target"
+
+	GameConfigMethodFactorPair GameConfigMethod = "factor_pair"
 )
 
+// GameConfig describes the problems of a game. MethodRanges overrides Range
+// for the methods it lists, and NegativeResults lets subtraction problems
+// come out below zero.
 type GameConfig struct {
-	Methods []GameConfigMethod `json:"methods"`
-	Range   GameConfigRange    `json:"range"`
+	Methods        []GameConfigMethod      `json:"methods"`
+	Range          GameConfigRange         `json:"range"`
+	ProblemCount   int                     `json:"problem_count,omitempty"`
+	MissingOperand bool                    `json:"missing_operand,omitempty"`
+	Expression     *GameConfigExpression   `json:"expression,omitempty"`
+	Fractions      *GameConfigFractions    `json:"fractions,omitempty"`
+	Decimals       *GameConfigDecimals     `json:"decimals,omitempty"`
+	Powers         *GameConfigPowers       `json:"powers,omitempty"`
+	Sequences      *GameConfigSequences    `json:"sequences,omitempty"`
+	Comparisons    *GameConfigComparisons  `json:"comparisons,omitempty"`
+	Estimation     *GameConfigEstimation   `json:"estimation,omitempty"`
+	WordProblems   *GameConfigWordProblems `json:"word_problems,omitempty"`
+	MultipleChoice bool                    `json:"multiple_choice,omitempty"`
+	Targets        *GameConfigTargets      `json:"targets,omitempty"`
+	TimesTables    *GameConfigTimesTables  `json:"times_tables,omitempty"`
+	Mode           GameMode                `json:"mode,omitempty"`
+	Adaptive       *GameConfigAdaptive     `json:"adaptive,omitempty"`
+
+	MethodRanges    map[GameConfigMethod]GameConfigRange `json:"method_ranges,omitempty"`
+	Exclude         []GameConfigExclusion                `json:"exclude,omitempty"`
+	NegativeResults bool                                 `json:"negative_results,omitempty"`
+}
+
+// GameMode is how problems are handed out. In a fixed game every player
+// races through the same problem set, in an adaptive one each player is
+// given problems at a level that follows how well they are doing, and in an
+// individual one each player works through a set of their own, different
+// from everyone else's but as long and as difficult. In a review game each
+// player works through the facts due in their own review queue.
+type GameMode string
+
+const (
+	GameModeFixed      GameMode = "fixed"
+	GameModeAdaptive   GameMode = "adaptive"
+	GameModeIndividual GameMode = "individual"
+	GameModeReview     GameMode = "review"
+)
+
+// GameConfigAdaptive tunes an adaptive game. Players start at StartLevel
+// and, judged on their last Window answers, move up a level when answering
+// accurately within TargetMs and down when struggling, staying between
+// MinLevel and MaxLevel.
+type GameConfigAdaptive struct {
+	MinLevel   int `json:"min_level,omitempty"`
+	MaxLevel   int `json:"max_level,omitempty"`
+	StartLevel int `json:"start_level,omitempty"`
+	Window     int `json:"window,omitempty"`
+	TargetMs   int `json:"target_ms,omitempty"`
+}
+
+// GameConfigExclusion names a kind of trivial problem to leave out.
+type GameConfigExclusion string
+
+const (
+	// GameConfigExcludeZero leaves out problems with an operand of 0, like x + 0.
+	GameConfigExcludeZero GameConfigExclusion = "zero"
+	// GameConfigExcludeOne leaves out multiplying or dividing by 1.
+	GameConfigExcludeOne GameConfigExclusion = "one"
+	// GameConfigExcludeSame leaves out x - x and x ÷ x.
+	GameConfigExcludeSame GameConfigExclusion = "same"
+)
+
+// GameConfigExpression shapes multi-step expression problems. Operators is how
+// many operators each expression has and Depth how deeply parentheses may
+// nest, with 0 meaning order of operations only.
+type GameConfigExpression struct {
+	Operations []GameConfigMethod `json:"operations,omitempty"`
+	Operators  int                `json:"operators"`
+	Depth      int                `json:"depth"`
+	MaxResult  int                `json:"max_result,omitempty"`
+}
+
+// GameConfigFractions shapes fraction problems. With RequireSimplestForm an
+// answer such as 2/4 is rejected when 1/2 is expected.
+type GameConfigFractions struct {
+	Operations          []GameConfigMethod `json:"operations,omitempty"`
+	MaxDenominator      int                `json:"max_denominator,omitempty"`
+	MixedNumbers        bool               `json:"mixed_numbers,omitempty"`
+	RequireSimplestForm bool               `json:"require_simplest_form,omitempty"`
+}
+
+// GameConfigDecimals shapes decimal and percentage problems. Places is the
+// number of digits after the point in operands. Without a Tolerance answers
+// must match exactly, otherwise any answer within Tolerance is accepted.
+type GameConfigDecimals struct {
+	Operations []GameConfigMethod `json:"operations,omitempty"`
+	Places     int                `json:"places,omitempty"`
+	Tolerance  *Value             `json:"tolerance,omitempty"`
+}
+
+// GameConfigPowers overrides the operand ranges of power and root problems,
+// which don't use GameConfig.Range. Bases applies to power, square and cube,
+// Exponents to power and power_of_ten, and Roots to square_root. Unset ranges
+// fall back to defaults sized for each method.
+type GameConfigPowers struct {
+	Bases             *GameConfigRange `json:"bases,omitempty"`
+	Exponents         *GameConfigRange `json:"exponents,omitempty"`
+	Roots             *GameConfigRange `json:"roots,omitempty"`
+	NegativeExponents bool             `json:"negative_exponents,omitempty"`
+}
+
+type SequenceKind string
+
+const (
+	SequenceKindArithmetic SequenceKind = "arithmetic"
+	SequenceKindGeometric  SequenceKind = "geometric"
+	SequenceKindSquare     SequenceKind = "square"
+	SequenceKindFibonacci  SequenceKind = "fibonacci"
+)
+
+// GameConfigSequences shapes sequence problems. Length is how many terms a
+// sequence has, counting the one left out, MaxStep bounds the difference of
+// arithmetic sequences and MaxRatio the ratio of geometric ones. Without
+// MissingTerm the player is always asked for the next term, with it any term
+// may be the one left out.
+type GameConfigSequences struct {
+	Kinds       []SequenceKind `json:"kinds,omitempty"`
+	Length      int            `json:"length,omitempty"`
+	MaxStep     int            `json:"max_step,omitempty"`
+	MaxRatio    int            `json:"max_ratio,omitempty"`
+	MissingTerm bool           `json:"missing_term,omitempty"`
+}
+
+// GameConfigComparisons picks the operations used on each side of a
+// comparison problem.
+type GameConfigComparisons struct {
+	Operations []GameConfigMethod `json:"operations,omitempty"`
+}
+
+// GameConfigEstimation shapes estimation problems. An estimate earns the
+// points of the best band it falls in, where Within is the largest error
+// allowed as a percentage of the true answer. DisplayMs is how long clients
+// should show each problem before hiding it.
+type GameConfigEstimation struct {
+	Operations []GameConfigMethod    `json:"operations,omitempty"`
+	Bands      []GameConfigErrorBand `json:"bands,omitempty"`
+	DisplayMs  int                   `json:"display_ms,omitempty"`
+}
+
+// GameConfigTargets shapes "reach the target" puzzles. Each puzzle has
+// Numbers numbers, Large of them drawn from 25, 50, 75 and 100 and the rest
+// from 1 to 10, and a target between MinTarget and MaxTarget. An expression
+// that misses earns the points of the best band it falls in, where Within is
+// how far from the target it may land.
+type GameConfigTargets struct {
+	Numbers   int                   `json:"numbers,omitempty"`
+	Large     *int                  `json:"large,omitempty"`
+	MinTarget int                   `json:"min_target,omitempty"`
+	MaxTarget int                   `json:"max_target,omitempty"`
+	Bands     []GameConfigErrorBand `json:"bands,omitempty"`
 }
 
+// GameConfigTimesTables turns a multiply and divide game into a drill of the
+// given tables. Every fact pairing a table with a multiplier comes up Repeats
+// times, as a product and, when dividing, as the matching quotient, in
+// shuffled order.
+type GameConfigTimesTables struct {
+	Tables      []int            `json:"tables"`
+	Multipliers *GameConfigRange `json:"multipliers,omitempty"`
+	Repeats     int              `json:"repeats,omitempty"`
+}
+
+type GameConfigErrorBand struct {
+	Within int `json:"within"`
+	Points int `json:"points"`
+}
+
+// GameConfigWordProblems turns add, subtract, multiply and divide problems
+// into word problems written in Locale, using templates suited to Grade.
+// A Grade of 0 allows templates for any grade.
+type GameConfigWordProblems struct {
+	Locale string `json:"locale,omitempty"`
+	Grade  int    `json:"grade,omitempty"`
+}
+
+// GameProblemSlot names the part of a problem the player has to fill in.
+type GameProblemSlot string
+
+const (
+	GameProblemSlotNumber1 GameProblemSlot = "number1"
+	GameProblemSlotNumber2 GameProblemSlot = "number2"
+	GameProblemSlotAnswer  GameProblemSlot = "answer"
+)
+
+// GameProblem is Number1 Method Number2 = Answer. Blank says which of the
+// three the player is asked for, and is empty when it is the answer.
 type GameProblem struct {
 	Number1 int              `json:"number1"`
 	Number2 int              `json:"number2"`
 	Method  GameConfigMethod `json:"method"`
-	Answer  int              `json:"answer"`
+	Answer  Value            `json:"answer"`
+	Blank   GameProblemSlot  `json:"blank,omitempty"`
+
+	// Expression problems carry the whole tree and its canonical text
+	// instead of Number1 and Number2.
+	Expression *Expression `json:"expression,omitempty"`
+	Text       string      `json:"text,omitempty"`
+
+	// Problems over non-integer operands, such as fractions, apply
+	// Operation to Operands instead.
+	Operation GameConfigMethod `json:"operation,omitempty"`
+	Operands  []Value          `json:"operands,omitempty"`
+
+	// Sequence problems show Terms with the one to find left null.
+	Terms []Value `json:"terms,omitempty"`
+
+	// Comparison problems ask how the values of the two Sides relate.
+	Sides []*Expression `json:"sides,omitempty"`
+
+	// DisplayMs is how long the problem is shown for, when it is only
+	// shown briefly.
+	DisplayMs int `json:"display_ms,omitempty"`
+
+	// In multiple choice games the player answers with the ID of one of
+	// the Choices.
+	Choices []Choice `json:"choices,omitempty"`
+
+	// Target puzzles are answered with an expression over Operands that
+	// comes to Target. Solution is one that does.
+	Target   int    `json:"target,omitempty"`
+	Solution string `json:"solution,omitempty"`
+
+	// Open-ended problems are answered by filling in each of Fields.
+	Fields []string `json:"fields,omitempty"`
+}
+
+type Choice struct {
+	ID    string `json:"id"`
+	Value Value  `json:"value"`
+}
+
+// Expression is a node of an arithmetic expression tree. Leaves hold a
+// Value, other nodes apply Operator to Left and Right.
+type Expression struct {
+	Operator GameConfigMethod `json:"operator,omitempty"`
+	Value    *int             `json:"value,omitempty"`
+	Left     *Expression      `json:"left,omitempty"`
+	Right    *Expression      `json:"right,omitempty"`
+}
+
+// Submission is a player's answer to one problem. Most problems take a
+// single Answer, multiple choice problems the ID of a Choice, target puzzles
+// an Expression and open-ended problems a value for each of their Fields.
+type Submission struct {
+	Answer     Value            `json:"answer"`
+	Choice     string           `json:"choice,omitempty"`
+	Expression string           `json:"expression,omitempty"`
+	Fields     map[string]Value `json:"fields,omitempty"`
+}
+
+// AttemptAnswer is one answer in a solo attempt at a fixed problem set.
+// TimeMs is how long the player reports spending on the problem.
+type AttemptAnswer struct {
+	Submission
+	TimeMs  int  `json:"time_ms"`
+	Correct bool `json:"correct"`
+	Points  int  `json:"points"`
+}
+
+// DifficultySample is how a player did on a problem, kept to calibrate how
+// difficult problems are rated.
+type DifficultySample struct {
+	Problem GameProblem `json:"problem"`
+	Correct bool        `json:"correct"`
+	TimeMs  int         `json:"time_ms"`
+}
+
+// AttemptResult is one player's run through a fixed problem set, such as the daily challenge.
+type AttemptResult struct {
+	UserID      uuid.UUID       `json:"user_id"`
+	Username    string          `json:"username"`
+	Answers     []AttemptAnswer `json:"answers"`
+	Points      int             `json:"points"`
+	TotalTimeMs int             `json:"total_time_ms"`
+	StartedAt   time.Time       `json:"started_at"`
+	CompletedAt *time.Time      `json:"completed_at,omitempty"`
+}
+
+type DailyChallenge struct {
+	Date       string         `json:"date"`
+	GameConfig GameConfig     `json:"game_config"`
+	Attempt    *AttemptResult `json:"attempt"`
+}
+
+type ChallengeStatus string
+
+const (
+	ChallengeStatusPending   ChallengeStatus = "pending"
+	ChallengeStatusOpen      ChallengeStatus = "open"
+	ChallengeStatusAccepted  ChallengeStatus = "accepted"
+	ChallengeStatusCompleted ChallengeStatus = "completed"
+)
+
+// Challenge is an asynchronous match: the challenger plays a seeded problem
+// set first and the opponent plays the identical set later.
+type Challenge struct {
+	ID         uuid.UUID       `json:"id"`
+	GameConfig GameConfig      `json:"game_config"`
+	Seed       int64           `json:"seed,omitempty"`
+	Problems   []GameProblem   `json:"problems,omitempty"`
+	Status     ChallengeStatus `json:"status"`
+	Challenger AttemptResult   `json:"challenger"`
+	Opponent   *AttemptResult  `json:"opponent,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+	ExpiresAt  time.Time       `json:"expires_at"`
+}
+
+// ChallengeProblemResult lines up both players' answers to one problem of a challenge.
+type ChallengeProblemResult struct {
+	Problem    GameProblem    `json:"problem"`
+	Challenger *AttemptAnswer `json:"challenger"`
+	Opponent   *AttemptAnswer `json:"opponent"`
+}
+
+// BotResponseTime is the normal distribution a bot's answer delay is drawn from.
+type BotResponseTime struct {
+	MeanMs   int `json:"mean_ms"`
+	StdDevMs int `json:"std_dev_ms"`
+}
+
+// BotErrorProfile weights the kinds of mistakes a bot makes when it answers wrong.
+type BotErrorProfile struct {
+	OffByOne       float64 `json:"off_by_one"`
+	WrongOperation float64 `json:"wrong_operation"`
+	DigitSwap      float64 `json:"digit_swap"`
+}
+
+type BotProfile struct {
+	Accuracy     float64         `json:"accuracy"`
+	ResponseTime BotResponseTime `json:"response_time"`
+	Errors       BotErrorProfile `json:"errors"`
+}
+
+type Bot struct {
+	ID       uuid.UUID  `json:"id"`
+	Username string     `json:"username"`
+	Profile  BotProfile `json:"profile"`
 }
 
 type SocketMessage struct {
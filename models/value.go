@@ -0,0 +1,340 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type ValueKind string
+
+// maxDecimalPlaces bounds decimals so their denominator fits in an int64.
+const maxDecimalPlaces = 12
+
+var relationSymbols = []string{"<", "=", ">"}
+
+const (
+	ValueKindInteger  ValueKind = "integer"
+	ValueKindFraction ValueKind = "fraction"
+	ValueKindDecimal  ValueKind = "decimal"
+	ValueKindBoolean  ValueKind = "boolean"
+	ValueKindList     ValueKind = "list"
+	ValueKindRelation ValueKind = "relation"
+)
+
+// Value is a problem answer, operand or player submission. Integers and
+// decimals go over the wire as plain JSON numbers, so existing clients keep
+// working, and fractions as strings such as "3/4" or the mixed number "1 1/2".
+//
+// Fractions are kept exactly as written, so 2/4 stays 2/4 and answer
+// checking can tell whether it was given in simplest form. Decimals are held
+// exactly as Numerator / 10^places rather than as floats.
+//
+// Yes/no answers are booleans, sent as JSON true or false, and answers made
+// of several numbers, like a prime factorization, are lists sent as arrays.
+// Relations are one of the strings "<", ">" or "=".
+type Value struct {
+	Kind        ValueKind
+	Numerator   int64
+	Denominator int64
+	Mixed       bool
+	Items       []Value
+}
+
+func IntegerValue(n int) Value {
+	return Value{Kind: ValueKindInteger, Numerator: int64(n), Denominator: 1}
+}
+
+func FractionValue(numerator, denominator int64) Value {
+	if denominator < 0 {
+		numerator, denominator = -numerator, -denominator
+	}
+	return Value{Kind: ValueKindFraction, Numerator: numerator, Denominator: denominator}
+}
+
+// MixedValue is a fraction displayed as a whole number part and a proper fraction.
+func MixedValue(numerator, denominator int64) Value {
+	v := FractionValue(numerator, denominator)
+	v.Mixed = true
+	return v
+}
+
+// DecimalValue is units / 10^places, e.g. DecimalValue(125, 2) is 1.25.
+func DecimalValue(units int64, places int) Value {
+	denominator := int64(1)
+	for i := 0; i < places; i++ {
+		denominator *= 10
+	}
+	return Value{Kind: ValueKindDecimal, Numerator: units, Denominator: denominator}
+}
+
+func BooleanValue(b bool) Value {
+	v := Value{Kind: ValueKindBoolean, Denominator: 1}
+	if b {
+		v.Numerator = 1
+	}
+	return v
+}
+
+func ListValue(items ...Value) Value {
+	return Value{Kind: ValueKindList, Items: items}
+}
+
+// RelationValue is the relation of a to b given their comparison, a negative
+// number for <, zero for = and a positive number for >.
+func RelationValue(cmp int) Value {
+	v := Value{Kind: ValueKindRelation, Denominator: 1}
+	switch {
+	case cmp < 0:
+		v.Numerator = -1
+	case cmp > 0:
+		v.Numerator = 1
+	}
+	return v
+}
+
+// IsNumber reports whether v is an integer, fraction or decimal.
+func (v Value) IsNumber() bool {
+	switch v.Kind {
+	case ValueKindInteger, ValueKindFraction, ValueKindDecimal:
+		return true
+	}
+	return false
+}
+
+// Bool returns a boolean value as a bool.
+func (v Value) Bool() bool {
+	return v.Numerator != 0
+}
+
+// Places returns how many digits a decimal value has after the point.
+func (v Value) Places() int {
+	places := 0
+	for d := v.Denominator; d > 1; d /= 10 {
+		places++
+	}
+	return places
+}
+
+// IsZero reports whether v holds no value at all, as opposed to the number zero.
+func (v Value) IsZero() bool {
+	return v.Kind == ""
+}
+
+// Int returns an integer value as an int.
+func (v Value) Int() int {
+	return int(v.Numerator)
+}
+
+func (v Value) String() string {
+	switch v.Kind {
+	case ValueKindInteger:
+		return strconv.FormatInt(v.Numerator, 10)
+	case ValueKindFraction:
+		if v.Mixed && (v.Numerator >= v.Denominator || -v.Numerator >= v.Denominator) {
+			sign := ""
+			numerator := v.Numerator
+			if numerator < 0 {
+				sign = "-"
+				numerator = -numerator
+			}
+			whole, rest := numerator/v.Denominator, numerator%v.Denominator
+			if rest == 0 {
+				return fmt.Sprintf("%s%d", sign, whole)
+			}
+			return fmt.Sprintf("%s%d %d/%d", sign, whole, rest, v.Denominator)
+		}
+		return fmt.Sprintf("%d/%d", v.Numerator, v.Denominator)
+	case ValueKindDecimal:
+		sign := ""
+		units := v.Numerator
+		if units < 0 {
+			sign = "-"
+			units = -units
+		}
+		if v.Denominator == 1 {
+			return fmt.Sprintf("%s%d", sign, units)
+		}
+		return fmt.Sprintf("%s%d.%0*d", sign, units/v.Denominator, v.Places(), units%v.Denominator)
+	case ValueKindBoolean:
+		return strconv.FormatBool(v.Bool())
+	case ValueKindRelation:
+		return relationSymbols[v.Numerator+1]
+	case ValueKindList:
+		texts := make([]string, len(v.Items))
+		for i, item := range v.Items {
+			texts[i] = item.String()
+		}
+		return strings.Join(texts, ", ")
+	}
+	return ""
+}
+
+func (v Value) MarshalJSON() ([]byte, error) {
+	switch v.Kind {
+	case "":
+		return []byte("null"), nil
+	case ValueKindInteger, ValueKindDecimal, ValueKindBoolean:
+		return []byte(v.String()), nil
+	case ValueKindList:
+		return json.Marshal(v.Items)
+	}
+	return json.Marshal(v.String())
+}
+
+func (v *Value) UnmarshalJSON(data []byte) error {
+	text := strings.TrimSpace(string(data))
+	if text == "null" {
+		*v = Value{}
+		return nil
+	}
+
+	switch text {
+	case "true", "false":
+		*v = BooleanValue(text == "true")
+		return nil
+	}
+
+	if strings.HasPrefix(text, "[") {
+		var items []Value
+		if err := json.Unmarshal(data, &items); err != nil {
+			return err
+		}
+		for _, item := range items {
+			if !item.IsNumber() {
+				return fmt.Errorf("invalid list item %s", item)
+			}
+		}
+		*v = ListValue(items...)
+		return nil
+	}
+
+	if strings.HasPrefix(text, `"`) {
+		if err := json.Unmarshal(data, &text); err != nil {
+			return err
+		}
+		parsed, err := ParseValue(text)
+		if err != nil {
+			return err
+		}
+		*v = parsed
+		return nil
+	}
+
+	parsed, err := parseNumber(text)
+	if err != nil {
+		return err
+	}
+	*v = parsed
+	return nil
+}
+
+// parseNumber reads a whole number or a decimal such as 2.90, keeping every
+// digit after the point.
+func parseNumber(text string) (Value, error) {
+	if n, err := strconv.ParseInt(text, 10, 64); err == nil {
+		return Value{Kind: ValueKindInteger, Numerator: n, Denominator: 1}, nil
+	}
+
+	whole, fraction, found := strings.Cut(text, ".")
+	if !found || fraction == "" || len(fraction) > maxDecimalPlaces || strings.Trim(fraction, "0123456789") != "" {
+		return Value{}, fmt.Errorf("invalid number %q", text)
+	}
+	units, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil || whole == "" || whole == "-" {
+		return Value{}, fmt.Errorf("invalid number %q", text)
+	}
+	return DecimalValue(units, len(fraction)), nil
+}
+
+// ParseValue reads a value written as text: "7", "2.5", "3/4", "-3/4",
+// "1 1/2", "yes" or "no", "<", ">" or "=", or a list of numbers such as "2, 2, 3" or "2 × 2 × 3".
+func ParseValue(text string) (Value, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return Value{}, errors.New("empty value")
+	}
+
+	switch strings.ToLower(text) {
+	case "yes", "true":
+		return BooleanValue(true), nil
+	case "no", "false":
+		return BooleanValue(false), nil
+	}
+	for i, symbol := range relationSymbols {
+		if text == symbol {
+			return RelationValue(i - 1), nil
+		}
+	}
+
+	if separator := listSeparator(text); separator != "" {
+		parts := strings.Split(text, separator)
+		items := make([]Value, len(parts))
+		for i, part := range parts {
+			item, err := ParseValue(part)
+			if err != nil {
+				return Value{}, err
+			}
+			if !item.IsNumber() {
+				return Value{}, fmt.Errorf("invalid list item %q", part)
+			}
+			items[i] = item
+		}
+		return ListValue(items...), nil
+	}
+
+	if !strings.Contains(text, "/") {
+		return parseNumber(text)
+	}
+
+	negative := strings.HasPrefix(text, "-")
+	text = strings.TrimPrefix(text, "-")
+	fields := strings.Fields(text)
+	if len(fields) == 0 || len(fields) > 2 {
+		return Value{}, fmt.Errorf("invalid fraction %q", text)
+	}
+
+	var whole int64
+	if len(fields) == 2 {
+		var err error
+		whole, err = strconv.ParseInt(fields[0], 10, 64)
+		if err != nil || whole < 0 {
+			return Value{}, fmt.Errorf("invalid mixed number %q", text)
+		}
+	}
+
+	parts := strings.Split(fields[len(fields)-1], "/")
+	if len(parts) != 2 {
+		return Value{}, fmt.Errorf("invalid fraction %q", text)
+	}
+	numerator, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || numerator < 0 {
+		return Value{}, fmt.Errorf("invalid fraction %q", text)
+	}
+	denominator, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || denominator <= 0 {
+		return Value{}, fmt.Errorf("invalid fraction %q", text)
+	}
+
+	numerator += whole * denominator
+	if negative {
+		numerator = -numerator
+	}
+	return Value{
+		Kind:        ValueKindFraction,
+		Numerator:   numerator,
+		Denominator: denominator,
+		Mixed:       len(fields) == 2,
+	}, nil
+}
+
+func listSeparator(text string) string {
+	for _, separator := range []string{",", "×", "*"} {
+		if strings.Contains(text, separator) {
+			return separator
+		}
+	}
+	return ""
+}